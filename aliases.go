@@ -0,0 +1,226 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// ==================== Aliases and Macros ====================
+
+// maxExpansionDepth stops aliases and macros that refer to each other from
+// expanding forever.
+const maxExpansionDepth = 10
+
+// checkShortcutName makes sure a new alias or macro name is one word and
+// doesn't hide a built-in command.
+func checkShortcutName(db *sql.DB, name string) error {
+	if name == "" || strings.ContainsFunc(name, unicode.IsSpace) {
+		return fmt.Errorf("names must be a single word")
+	}
+	if _, ok := getCommands(db)[name]; ok {
+		return fmt.Errorf("'%s' is a built-in command", name)
+	}
+	return nil
+}
+
+// restOfLine returns input after its first word, untouched so quoting
+// survives alias expansion.
+func restOfLine(input string) string {
+	input = strings.TrimLeftFunc(input, unicode.IsSpace)
+	if i := strings.IndexFunc(input, unicode.IsSpace); i >= 0 {
+		return input[i:]
+	}
+	return ""
+}
+
+// expandShortcut runs name if it is an alias or macro, reporting whether it
+// was one. An alias's definition is run with the rest of the line appended;
+// a macro's commands run in order until one fails.
+func expandShortcut(db *sql.DB, name, input string, depth int) (bool, error) {
+	definition, isAlias := config.Aliases[name]
+	commands, isMacro := config.Macros[name]
+	if !isAlias && !isMacro {
+		return false, nil
+	}
+	if depth >= maxExpansionDepth {
+		return true, fmt.Errorf("'%s' expands too many times; check your aliases and macros for a loop", name)
+	}
+
+	if isAlias {
+		return true, runExpanded(db, definition+restOfLine(input), depth+1)
+	}
+
+	if strings.TrimSpace(restOfLine(input)) != "" {
+		return true, fmt.Errorf("macro '%s' takes no arguments", name)
+	}
+	for _, line := range commands {
+		if interactive {
+			fmt.Printf("%s %s\n", theme.symbol("»"), line)
+		}
+		if err := runExpanded(db, line, depth+1); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// printShortcuts lists aliases and macros for help.
+func printShortcuts() {
+	if len(config.Aliases) > 0 {
+		fmt.Println("Aliases:")
+		for _, name := range slices.Sorted(maps.Keys(config.Aliases)) {
+			fmt.Printf("  %-10s = %s\n", name, config.Aliases[name])
+		}
+		fmt.Println()
+	}
+	if len(config.Macros) > 0 {
+		fmt.Println("Macros:")
+		for _, name := range slices.Sorted(maps.Keys(config.Macros)) {
+			fmt.Printf("  %-10s = %s\n", name, strings.Join(config.Macros[name], "; "))
+		}
+		fmt.Println()
+	}
+}
+
+// saveShortcuts saves the config with change applied to a copy of its
+// aliases and macros. The copy only replaces the config once it's saved, so a
+// failed save leaves things as they were.
+func saveShortcuts(change func(c *Config)) error {
+	updated := *config
+	updated.Aliases = maps.Clone(config.Aliases)
+	updated.Macros = maps.Clone(config.Macros)
+	change(&updated)
+
+	previous := config
+	config = &updated
+	if err := saveConfig(); err != nil {
+		config = previous
+		return err
+	}
+	return nil
+}
+
+func aliasCommandWithDB(db *sql.DB, args []string) error {
+	usage := fmt.Errorf(`usage: alias, alias <name> "<command>", or alias remove <name>`)
+
+	switch {
+	case len(args) == 0 || args[0] == "list":
+		if len(config.Aliases) == 0 {
+			fmt.Println(`No aliases yet. Add one with 'alias s "study -d m -c 3"'.`)
+			return nil
+		}
+		fmt.Println()
+		printShortcuts()
+		return nil
+
+	case args[0] == "remove" || args[0] == "rm":
+		if len(args) != 2 {
+			return usage
+		}
+		if _, ok := config.Aliases[args[1]]; !ok {
+			return fmt.Errorf("no alias named '%s'", args[1])
+		}
+		err := saveShortcuts(func(c *Config) { delete(c.Aliases, args[1]) })
+		if err != nil {
+			return err
+		}
+		fmt.Println(success("Removed alias '%s'", args[1]))
+		return nil
+
+	case len(args) < 2:
+		return usage
+	}
+
+	name, definition := args[0], strings.TrimSpace(strings.Join(args[1:], " "))
+	if err := checkShortcutName(db, name); err != nil {
+		return err
+	}
+	if _, ok := config.Macros[name]; ok {
+		return fmt.Errorf("'%s' is already a macro; remove it first", name)
+	}
+	if definition == "" {
+		return usage
+	}
+
+	err := saveShortcuts(func(c *Config) {
+		if c.Aliases == nil {
+			c.Aliases = map[string]string{}
+		}
+		c.Aliases[name] = definition
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Println(success("'%s' now runs '%s'", name, definition))
+	return nil
+}
+
+func macroCommandWithDB(db *sql.DB, args []string) error {
+	usage := fmt.Errorf(`usage: macro, macro <name> "<command>; <command>; ...", or macro remove <name>`)
+
+	switch {
+	case len(args) == 0 || args[0] == "list":
+		if len(config.Macros) == 0 {
+			fmt.Println(`No macros yet. Add one with 'macro morning "study -c 3; stat"'.`)
+			return nil
+		}
+		fmt.Println()
+		printShortcuts()
+		return nil
+
+	case args[0] == "remove" || args[0] == "rm":
+		if len(args) != 2 {
+			return usage
+		}
+		if _, ok := config.Macros[args[1]]; !ok {
+			return fmt.Errorf("no macro named '%s'", args[1])
+		}
+		err := saveShortcuts(func(c *Config) { delete(c.Macros, args[1]) })
+		if err != nil {
+			return err
+		}
+		fmt.Println(success("Removed macro '%s'", args[1]))
+		return nil
+
+	case len(args) < 2:
+		return usage
+	}
+
+	name := args[0]
+	if err := checkShortcutName(db, name); err != nil {
+		return err
+	}
+	if _, ok := config.Aliases[name]; ok {
+		return fmt.Errorf("'%s' is already an alias; remove it first", name)
+	}
+
+	lines, err := splitCommands(strings.Join(args[1:], " "))
+	if err != nil {
+		return err
+	}
+	var commands []string
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			commands = append(commands, line)
+		}
+	}
+	if len(commands) == 0 {
+		return usage
+	}
+
+	err = saveShortcuts(func(c *Config) {
+		if c.Macros == nil {
+			c.Macros = map[string][]string{}
+		}
+		c.Macros[name] = commands
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Println(success("'%s' now runs: %s", name, strings.Join(commands, "; ")))
+	return nil
+}
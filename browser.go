@@ -0,0 +1,202 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ==================== Browser ====================
+
+const (
+	openerKey  = "opener"
+	openerNone = "none" // Print URLs instead of launching anything
+)
+
+// lastListing holds the problems most recently printed by study or search,
+// so 'open <n>' can refer to them by position.
+var lastListing []Problem
+
+// leetcodeSlug derives the LeetCode URL slug from a problem title,
+// e.g. "Two Sum II Input Array Is Sorted" -> "two-sum-ii-input-array-is-sorted".
+func leetcodeSlug(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+			dash = false
+		default:
+			if !dash && b.Len() > 0 {
+				b.WriteByte('-')
+				dash = true
+			}
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// problemURL returns the problem's explicit URL, or for LeetCode problems one
+// built from its slug (derived from the title unless overridden). Problems
+// from other sources without a URL return "".
+func problemURL(p Problem) string {
+	if p.URL != "" {
+		return p.URL
+	}
+	if p.Source != "" && p.Source != sourceLeetCode {
+		return ""
+	}
+
+	slug := p.Slug
+	if slug == "" {
+		slug = leetcodeSlug(p.Title)
+	}
+	return "https://leetcode.com/problems/" + slug + "/"
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// hyperlink wraps text in an OSC 8 terminal hyperlink when writing to a
+// terminal. Terminals without OSC 8 support show the plain text.
+func hyperlink(url, text string) string {
+	if url == "" || !isTerminal(os.Stdout) {
+		return text
+	}
+	return "\033]8;;" + url + "\033\\" + text + "\033]8;;\033\\"
+}
+
+// openerCommand builds the command that opens url. A configured opener may
+// contain %s for the URL; otherwise the URL is appended as the last argument.
+func openerCommand(opener, url string) (*exec.Cmd, error) {
+	if opener == "" {
+		switch runtime.GOOS {
+		case "darwin":
+			return exec.Command("open", url), nil
+		case "windows":
+			return exec.Command("rundll32", "url.dll,FileProtocolHandler", url), nil
+		default:
+			return exec.Command("xdg-open", url), nil
+		}
+	}
+
+	parts, err := splitArgs(opener)
+	if err != nil || len(parts) == 0 {
+		return nil, fmt.Errorf("invalid opener %q", opener)
+	}
+
+	substituted := false
+	for i, part := range parts {
+		if strings.Contains(part, "%s") {
+			parts[i] = strings.ReplaceAll(part, "%s", url)
+			substituted = true
+		}
+	}
+	if !substituted {
+		parts = append(parts, url)
+	}
+	return exec.Command(parts[0], parts[1:]...), nil
+}
+
+// getOpener returns the configured opener, falling back to $BROWSER.
+// An empty result means the platform default.
+func getOpener(db *sql.DB) (string, error) {
+	opener, ok, err := getSetting(db, openerKey)
+	if err != nil || ok {
+		return opener, err
+	}
+	return os.Getenv("BROWSER"), nil
+}
+
+// openProblem launches the browser for p, or prints its URL when the opener
+// is "none" or fails to start.
+func openProblem(db *sql.DB, p Problem) error {
+	msg, err := launchProblem(db, p)
+	if err != nil {
+		return err
+	}
+	fmt.Println(msg)
+	return nil
+}
+
+// launchProblem is openProblem for callers that show the outcome themselves:
+// it returns what happened instead of printing it.
+func launchProblem(db *sql.DB, p Problem) (string, error) {
+	url := problemURL(p)
+	if url == "" {
+		return "", fmt.Errorf("'%s' has no URL; add one with 'problem edit --url'", p.Title)
+	}
+
+	opener, err := getOpener(db)
+	if err != nil {
+		return "", err
+	}
+	if opener == openerNone {
+		return url, nil
+	}
+
+	cmd, err := openerCommand(opener, url)
+	if err != nil {
+		return "", err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Sprintf("Couldn't launch a browser (%v); visit %s", err, url), nil
+	}
+	// Don't leave a zombie behind for openers that exit immediately
+	go cmd.Wait()
+
+	return fmt.Sprintf("Opened %s", url), nil
+}
+
+func openFlags(opener *string) *flag.FlagSet {
+	fs := newFlagSet("open")
+	fs.StringVar(opener, "set-opener", "", "Command used to open URLs (%s is replaced by the URL; 'none' prints it, 'default' resets)")
+	return fs
+}
+
+func openCommandWithDB(db *sql.DB, args []string) error {
+	var opener string
+	positional, err := parseInterspersed(openFlags(&opener), args)
+	if err != nil {
+		return err
+	}
+
+	if opener != "" {
+		if opener == "default" {
+			err = deleteSetting(db, openerKey)
+		} else {
+			err = setSetting(db, openerKey, opener)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Println(success("Opener set to %s", opener))
+		if len(positional) == 0 {
+			return nil
+		}
+	}
+
+	if len(positional) == 0 {
+		return fmt.Errorf("usage: open <n> (from the last study/search list) or open <title|LC number>")
+	}
+
+	query := strings.Join(positional, " ")
+	if n, err := strconv.Atoi(query); err == nil && n >= 1 && n <= len(lastListing) {
+		return openProblem(db, lastListing[n-1])
+	}
+
+	problem, err := resolveProblem(db, query)
+	if err != nil {
+		return err
+	}
+	return openProblem(db, problem)
+}
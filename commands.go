@@ -5,9 +5,14 @@ import (
 	"database/sql"
 	"flag"
 	"fmt"
+	"maps"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
+
+	"golang.org/x/term"
 )
 
 var shortToLong = map[string]string{
@@ -17,79 +22,130 @@ var shortToLong = map[string]string{
 	"a": "any",
 }
 
-func helpCommand(args []string) error {
-	fmt.Println()
-	fmt.Println("Available Commands:")
-	fmt.Println("==================")
-
-	for _, cmd := range getCommands(nil) {
-		fmt.Printf("  %-10s - %s\n", cmd.Name, cmd.Description)
-	}
-	fmt.Println()
-	fmt.Println("Example usage:")
-	fmt.Println("  study --difficulty easy --count 5")
-	fmt.Println("  study -d medium -c 3")
-	fmt.Println()
-	return nil
-}
-
 func exitCommand(args []string) error {
-	fmt.Println("Thanks for using GoStudyNeetCode! Happy coding! 👋")
-	os.Exit(0)
-	return nil
+	return errExit
 }
 
-func studyCommandWithDB(db *sql.DB, args []string) error {
-	// Create a new FlagSet for this command
-	fs := flag.NewFlagSet("study", flag.ContinueOnError)
-
-	var difficulty string
-	var count int
+type studyOptions struct {
+	Filter ProblemFilter
+	Count  int
 
-	// Define flags
-	fs.StringVar(&difficulty, "difficulty", "any", "Difficulty level (easy, medium, hard, any OR e, m, h, a)")
-	fs.StringVar(&difficulty, "d", "any", "Short for difficulty")
+	// Answers for scripts, instead of the questions asked after the list
+	Marks      []studyMark
+	AddRelated bool
+}
 
-	fs.IntVar(&count, "count", 1, "Number of questions")
-	fs.IntVar(&count, "c", 1, "Short for count")
+func studyFlags(o *studyOptions) *flag.FlagSet {
+	fs := newFlagSet("study")
+	o.Filter.Difficulty = settings.StudyDifficulty
+	filterFlags(fs, &o.Filter)
+	fs.IntVar(&o.Count, "count", settings.StudyCount, "Number of questions")
+	fs.IntVar(&o.Count, "c", settings.StudyCount, "Short for count")
+	fs.StringVar(&o.Filter.Company, "company", "", "Only problems asked by this company, favoring the most frequent")
+	fs.Func("mark", "Mark listed problem n done with a rating, as `n:rating` (repeatable)", func(s string) error {
+		m, err := parseStudyMark(s)
+		if err != nil {
+			return err
+		}
+		if slices.ContainsFunc(o.Marks, func(prev studyMark) bool { return prev.Num == m.Num }) {
+			return fmt.Errorf("problem %d is marked more than once", m.Num)
+		}
+		o.Marks = append(o.Marks, m)
+		return nil
+	})
+	fs.BoolVar(&o.AddRelated, "add-related", false, "With --mark, add related problems to the list after a Hard rating")
+	return fs
+}
 
-	// Parse the flags
-	err := fs.Parse(args)
-	if err != nil {
+func studyCommandWithDB(db *sql.DB, args []string) error {
+	var opts studyOptions
+	if err := studyFlags(&opts).Parse(args); err != nil {
 		return err
 	}
 
 	// Convert short form to long form if needed
-	if val, ok := shortToLong[difficulty]; ok {
-		difficulty = val
+	if val, ok := shortToLong[opts.Filter.Difficulty]; ok {
+		opts.Filter.Difficulty = val
 	}
 
-	problems, err := selectStudyProblems(db, difficulty, count)
+	problems, err := selectStudyProblems(db, opts.Filter, opts.Count)
 	if err != nil {
 		return err
 	}
 
-	fmt.Println("\n📚 Your Study Problems:")
-	fmt.Println("========================")
+	table := listingTable("\n"+theme.icon("📚")+"Your Study Problems:", "Topic")
 	for i, p := range problems {
-		fmt.Printf("%d. [LC %d] %s (%s) - %s\n", i+1, p.LeetcodeNumber, p.Title, p.Difficulty, p.Grouping)
+		table.AddRow(fmt.Sprintf("%d.", i+1), "["+p.Ref()+"]", hyperlink(problemURL(p), p.Title), p.Difficulty, p.Grouping)
 	}
+	table.Print()
+	lastListing = append(lastListing[:0], problems...)
 	fmt.Println()
 
+	for _, m := range opts.Marks {
+		if m.Num > len(problems) {
+			return fmt.Errorf("--mark %d: only %d problems were listed", m.Num, len(problems))
+		}
+	}
+	if len(opts.Marks) > 0 || !interactive {
+		return markStudyProblems(db, problems, opts.Marks, opts.AddRelated)
+	}
+
 	// Ask if user wants to mark any as completed
-	reader := bufio.NewReader(os.Stdin)
+	reader := stdin
+	hintsUsed := map[int]int{}
 	for len(problems) > 0 {
-		fmt.Print("Mark any as completed? (y/n): ")
-		response, _ := reader.ReadString('\n')
+		fmt.Print("Mark any as completed? (y/n, r to read a statement, h for a hint): ")
+		response, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
 		response = strings.TrimSpace(strings.ToLower(response))
 
 		if response == "n" || response == "no" {
 			break
 		}
 
+		if response == "r" || response == "read" {
+			fmt.Print("Enter problem number to read: ")
+			input, err := reader.ReadString('\n')
+			if err != nil {
+				return err
+			}
+			num, err := strconv.Atoi(strings.TrimSpace(input))
+			if err != nil || num < 1 || num > len(problems) {
+				fmt.Printf("Invalid problem number: %s\n", strings.TrimSpace(input))
+				continue
+			}
+			if err := showStatement(db, problems[num-1]); err != nil {
+				fmt.Printf("Error reading statement: %v\n", err)
+			}
+			continue
+		}
+
+		if response == "h" || response == "hint" {
+			fmt.Print("Enter problem number for a hint: ")
+			input, err := reader.ReadString('\n')
+			if err != nil {
+				return err
+			}
+			num, err := strconv.Atoi(strings.TrimSpace(input))
+			if err != nil || num < 1 || num > len(problems) {
+				fmt.Printf("Invalid problem number: %s\n", strings.TrimSpace(input))
+				continue
+			}
+			problem := problems[num-1]
+			if hintsUsed[problem.ID], err = revealHint(db, problem, hintsUsed[problem.ID]); err != nil {
+				fmt.Printf("Error reading hints: %v\n", err)
+			}
+			continue
+		}
+
 		if response == "y" || response == "yes" {
 			fmt.Print("Enter problem number (e.g. 1 or 3): ")
-			input, _ := reader.ReadString('\n')
+			input, err := reader.ReadString('\n')
+			if err != nil {
+				return err
+			}
 			input = strings.TrimSpace(input)
 
 			if input != "" {
@@ -102,31 +158,39 @@ func studyCommandWithDB(db *sql.DB, args []string) error {
 
 				problem := problems[num-1]
 
-				// Ask for effort rating
-				fmt.Printf("\nHow hard was '%s'? (1=Easy, 2=Medium, 3=Hard): ", problem.Title)
-				ratingStr, _ := reader.ReadString('\n')
-				ratingStr = strings.TrimSpace(ratingStr)
-				rating, err := strconv.Atoi(ratingStr)
-				if err != nil || rating < 1 || rating > 3 {
+				rating, err := readRating(reader, problem.Title)
+				if err != nil {
+					return err
+				}
+				if rating == 0 {
 					fmt.Println("Invalid rating, skipping...")
 					continue
 				}
 
 				// Update the database
-				if err := updateProblemCompletion(db, problem.Title, rating); err != nil {
+				if err := updateProblemCompletion(db, problem.Title, rating, hintsUsed[problem.ID], time.Now()); err != nil {
 					fmt.Printf("Error updating problem: %v\n", err)
 				} else {
-					fmt.Printf("\033[32m✓ Marked '%s' as completed with effort rating %d\033[0m\n", problem.Title, rating)
+					fmt.Println(success("Marked '%s' as completed with effort rating %d", problem.Title, rating))
+					if n := hintsUsed[problem.ID]; n > 0 {
+						fmt.Printf("  (%d hints used; scheduled as a harder solve)\n", n)
+					}
 
 					// Remove from slice using 0-indexed position
 					idx := num - 1
 					problems = append(problems[:idx], problems[idx+1:]...)
 
+					// Practice the siblings of a problem we struggled with
+					if rating == 3 {
+						problems = offerRelatedProblems(db, reader, problem, problems, false)
+					}
+					lastListing = append(lastListing[:0], problems...)
+
 					// Show updated list
 					if len(problems) > 0 {
 						fmt.Println("\nRemaining problems:")
 						for i, p := range problems {
-							fmt.Printf("%d. [LC %d] %s\n", i+1, p.LeetcodeNumber, p.Title)
+							fmt.Printf("%d. [%s] %s\n", i+1, p.Ref(), hyperlink(problemURL(p), p.Title))
 						}
 						fmt.Println()
 					}
@@ -138,60 +202,302 @@ func studyCommandWithDB(db *sql.DB, args []string) error {
 	return nil
 }
 
-func reviewCommandWithDB(db *sql.DB, args []string) error {
-	fs := flag.NewFlagSet("review", flag.ContinueOnError)
+// studyMark is a --mark answer: mark listed problem Num done with Rating.
+type studyMark struct {
+	Num    int
+	Rating int
+}
+
+func parseStudyMark(s string) (studyMark, error) {
+	numStr, ratingStr, ok := strings.Cut(s, ":")
+	if !ok {
+		return studyMark{}, fmt.Errorf("expected n:rating, e.g. 1:2")
+	}
+	num, err := strconv.Atoi(numStr)
+	if err != nil || num < 1 {
+		return studyMark{}, fmt.Errorf("invalid problem number: %s", numStr)
+	}
+	rating, err := strconv.Atoi(ratingStr)
+	if err != nil || rating < 1 || rating > 3 {
+		return studyMark{}, fmt.Errorf("rating must be 1 (Easy), 2 (Medium) or 3 (Hard)")
+	}
+	return studyMark{Num: num, Rating: rating}, nil
+}
+
+// markStudyProblems applies --mark answers without asking anything. Numbers
+// refer to the list as first printed.
+func markStudyProblems(db *sql.DB, problems []Problem, marks []studyMark, addRelated bool) error {
+	queue := slices.Clone(problems)
+	for _, m := range marks {
+		problem := problems[m.Num-1]
+		if err := updateProblemCompletion(db, problem.Title, m.Rating, 0, time.Now()); err != nil {
+			return fmt.Errorf("update problem: %w", err)
+		}
+		fmt.Println(success("Marked '%s' as completed with effort rating %d", problem.Title, m.Rating))
 
-	var difficulty string
-	fs.StringVar(&difficulty, "difficulty", "any", "Filter by difficulty (easy, medium, hard, any)")
-	fs.StringVar(&difficulty, "d", "any", "Short for difficulty")
+		queue = slices.DeleteFunc(queue, func(q Problem) bool { return q.ID == problem.ID })
+		if m.Rating == 3 {
+			queue = offerRelatedProblems(db, nil, problem, queue, addRelated)
+		}
+	}
+	lastListing = append(lastListing[:0], queue...)
+	return nil
+}
+
+type doneOptions struct {
+	Rating int
+	Hints  int
+	Date   string
+}
+
+func doneFlags(o *doneOptions) *flag.FlagSet {
+	fs := newFlagSet("done")
+	fs.IntVar(&o.Rating, "rating", 0, "Effort rating (1=Easy, 2=Medium, 3=Hard)")
+	fs.IntVar(&o.Rating, "r", 0, "Short for rating")
+	fs.IntVar(&o.Hints, "hints", 0, "Number of hints you needed")
+	fs.StringVar(&o.Date, "date", "", "Date solved (`YYYY-MM-DD`), defaults to now")
+	return fs
+}
+
+func doneCommandWithDB(db *sql.DB, args []string) error {
+	var opts doneOptions
+	positional, err := parseInterspersed(doneFlags(&opts), args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		return fmt.Errorf("usage: done <title|LC number> --rating 1|2|3 [--hints n] [--date YYYY-MM-DD]")
+	}
+	if opts.Rating < 1 || opts.Rating > 3 {
+		return fmt.Errorf("--rating must be 1 (Easy), 2 (Medium) or 3 (Hard)")
+	}
+	if opts.Hints < 0 || opts.Hints > len(hintLevels) {
+		return fmt.Errorf("--hints must be between 0 and %d", len(hintLevels))
+	}
+
+	completedAt := time.Now()
+	if opts.Date != "" {
+		// The user means a day on their own calendar, not in UTC
+		day, err := time.ParseInLocation("2006-01-02", opts.Date, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid date %q (expected YYYY-MM-DD)", opts.Date)
+		}
+		today := completedAt.Format("2006-01-02")
+		if day.Format("2006-01-02") > today {
+			return fmt.Errorf("date %s is in the future", opts.Date)
+		}
+		// Anything but today is logged at noon, well away from either end of the day
+		if day.Format("2006-01-02") != today {
+			completedAt = day.Add(12 * time.Hour)
+		}
+	}
+
+	problem, err := resolveProblem(db, strings.Join(positional, " "))
+	if err != nil {
+		return err
+	}
+
+	if err := updateProblemCompletion(db, problem.Title, opts.Rating, opts.Hints, completedAt); err != nil {
+		return fmt.Errorf("update problem: %w", err)
+	}
+
+	fmt.Println(success("Marked '%s' as completed on %s with effort rating %d",
+		problem.Title, completedAt.Format("Jan 2, 2006"), opts.Rating))
+	return nil
+}
+
+// offerRelatedProblems suggests up to two unseen problems related to one
+// rated Hard and returns queue with any the user accepts appended. With a nil
+// reader nothing is asked, and autoAdd decides.
+func offerRelatedProblems(db *sql.DB, reader *bufio.Reader, problem Problem, queue []Problem, autoAdd bool) []Problem {
+	candidates, err := getRelatedProblems(db, problem, 2+len(queue), true)
+	if err != nil {
+		fmt.Printf("Error finding related problems: %v\n", err)
+		return queue
+	}
+
+	var suggestions []Problem
+	for _, c := range candidates {
+		queued := slices.ContainsFunc(queue, func(q Problem) bool { return q.ID == c.ID })
+		if !queued && len(suggestions) < 2 {
+			suggestions = append(suggestions, c.Problem)
+		}
+	}
+	if len(suggestions) == 0 {
+		return queue
+	}
+
+	fmt.Println("\nRelated problems you haven't tried yet:")
+	for _, s := range suggestions {
+		fmt.Printf("  - [%s] %s (%s)\n", s.Ref(), hyperlink(problemURL(s), s.Title), s.Difficulty)
+	}
+	if reader == nil {
+		if !autoAdd {
+			return queue
+		}
+		fmt.Println("Added them to today's list.")
+		return append(queue, suggestions...)
+	}
+	fmt.Print("Add them to today's list? (y/n): ")
+	response, _ := reader.ReadString('\n')
+	response = strings.TrimSpace(strings.ToLower(response))
+	if response != "y" && response != "yes" {
+		return queue
+	}
+	return append(queue, suggestions...)
+}
+
+// parseInterspersed parses flags that may appear before, after or between
+// positional arguments, returning the positional arguments in order.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// readRating asks for an effort rating. It returns 0 when the answer isn't
+// a valid rating.
+func readRating(reader *bufio.Reader, title string) (int, error) {
+	fmt.Printf("\nHow hard was '%s'? (1=Easy, 2=Medium, 3=Hard): ", title)
+	ratingStr, err := reader.ReadString('\n')
+	if err != nil {
+		return 0, err
+	}
+	rating, err := strconv.Atoi(strings.TrimSpace(ratingStr))
+	if err != nil || rating < 1 || rating > 3 {
+		return 0, nil
+	}
+	return rating, nil
+}
 
+type reviewOptions struct {
+	Filter ProblemFilter
+	Sort   string
+	Status string
+	Limit  int
+	Page   int
+}
+
+func reviewFlags(o *reviewOptions) *flag.FlagSet {
+	fs := newFlagSet("review")
+	filterFlags(fs, &o.Filter)
+	fs.StringVar(&o.Filter.Topic, "topic", "", "Only problems whose topic contains this, e.g. graph")
+	fs.StringVar(&o.Sort, "sort", "next", "Order by next, last, ef, lapses or title")
+	fs.StringVar(&o.Status, "status", "", "Only overdue, today, soon or future reviews")
+	fs.IntVar(&o.Limit, "limit", 0, "Show this many problems per page instead of paging to fit the screen")
+	fs.IntVar(&o.Page, "page", 1, "Which page to show with --limit")
+	return fs
+}
+
+func reviewCommandWithDB(db *sql.DB, args []string) error {
+	var opts reviewOptions
+	fs := reviewFlags(&opts)
 	err := fs.Parse(args)
 	if err != nil {
 		return err
 	}
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if set["page"] && opts.Limit == 0 {
+		return fmt.Errorf("--page needs --limit to say how many problems are on a page")
+	}
 
-	if val, ok := shortToLong[difficulty]; ok {
-		difficulty = val
+	if val, ok := shortToLong[opts.Filter.Difficulty]; ok {
+		opts.Filter.Difficulty = val
+	}
+	opts.Sort, opts.Status = strings.ToLower(opts.Sort), strings.ToLower(opts.Status)
+	if _, ok := reviewSorts[opts.Sort]; !ok {
+		return fmt.Errorf("unknown sort %q (use %s)", opts.Sort, strings.Join(slices.Sorted(maps.Keys(reviewSorts)), ", "))
+	}
+	if opts.Status != "" && !slices.Contains(reviewStatuses, opts.Status) {
+		return fmt.Errorf("unknown status %q (use %s)", opts.Status, strings.Join(reviewStatuses, ", "))
+	}
+	if opts.Limit < 0 || opts.Page < 1 {
+		return fmt.Errorf("--limit can't be negative and --page starts at 1")
 	}
 
-	reviews, err := getReviewHistory(db, difficulty)
+	reviews, err := getReviewHistory(db, opts.Filter, opts.Sort, opts.Status)
 	if err != nil {
 		return err
 	}
 
 	if len(reviews) == 0 {
+		if opts.Filter != (ProblemFilter{Difficulty: "any"}) || opts.Status != "" {
+			fmt.Println("\nNo completed problems match those filters.")
+			return nil
+		}
 		fmt.Println("\nNo completed problems yet. Complete some problems first!")
 		return nil
 	}
 
-	fmt.Println("\n📊 Review History:")
-	fmt.Println("====================================================================================")
-	fmt.Printf("%-4s %-40s %-10s %-15s %-15s\n", "Stat", "Problem", "Difficulty", "Last Done", "Next Review")
-	fmt.Println("------------------------------------------------------------------------------------")
-
-	for _, r := range reviews {
-		status, _ := getReviewStatus(r.DaysUntilReview)
-		lastDone := formatReviewDate(r.LastCompletedAt)
-		nextReview := formatReviewDate(r.NextReviewDate)
+	if opts.Limit > 0 {
+		pages := (len(reviews) + opts.Limit - 1) / opts.Limit
+		if opts.Page > pages {
+			return fmt.Errorf("page %d is past the end; there are %d pages of %d", opts.Page, pages, opts.Limit)
+		}
+		table, start := reviewTable(reviews), (opts.Page-1)*opts.Limit
+		table.PrintRows(start, min(start+opts.Limit, len(reviews)))
+		if pages > 1 {
+			fmt.Printf("Page %d of %d (%d problems)\n", opts.Page, pages, len(reviews))
+		}
+		fmt.Println()
+		return nil
+	}
 
-		fmt.Printf("%s   %-40s %-10s %-15s %-15s\n",
-			status,
-			truncate(r.Title, 40),
-			r.Difficulty,
-			lastDone,
-			nextReview,
-		)
+	// Page long lists to fit the screen when someone's there to turn pages
+	if interactive {
+		if _, height, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
+			if size := max(height-reviewPagerChrome, 3); len(reviews) > size {
+				return pageTable(reviewTable(reviews), size)
+			}
+		}
 	}
+	table := reviewTable(reviews)
+	table.Print()
 	fmt.Println()
 
 	return nil
 }
 
-func truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
+// reviewPagerChrome is how many lines of a screen the title, header and
+// prompt around a page of reviews take up.
+const reviewPagerChrome = 7
+
+func reviewTable(reviews []ReviewInfo) Table {
+	table := Table{
+		Title: "\n" + theme.icon("📊") + "Review History:",
+		Columns: []Column{
+			{Header: "Status"},
+			{Header: "Problem", Flex: true, Min: 12},
+			{Header: "Difficulty"},
+			{Header: "Last Done"},
+			{Header: "Next Review"},
+			{Header: "EF", Right: true},
+			{Header: "Lapses", Right: true},
+		},
+	}
+	for _, r := range reviews {
+		label, _ := getReviewStatus(r.DaysUntilReview)
+		table.AddRow(
+			theme.paint(reviewRoles[label], label),
+			r.Title,
+			r.Difficulty,
+			formatReviewDate(r.LastCompletedAt),
+			formatReviewDate(r.NextReviewDate),
+			fmt.Sprintf("%.2f", r.EasinessFactor.Float64),
+			strconv.Itoa(r.Lapses),
+		)
 	}
-	return s[:maxLen-3] + "..."
+	return table
 }
 
 func getCommands(db *sql.DB) map[string]CliCommand {
@@ -199,16 +505,24 @@ func getCommands(db *sql.DB) map[string]CliCommand {
 		"help": {
 			Name:        "help",
 			Description: "Display command for utilizing CLI tool",
+			Usage:       "help [command]",
+			Examples:    []string{"help", "help study"},
 			Callback:    helpCommand,
 		},
 		"exit": {
 			Name:        "exit",
 			Description: "Exit the application",
+			Usage:       "exit",
 			Callback:    exitCommand,
 		},
 		"study": {
 			Name:        "study",
 			Description: "Get your daily questions to study",
+			Usage:       "study [-c n] [-d difficulty] [--tag tag] [--company name] [--mark n:rating]... [--add-related]",
+			Examples:    []string{"study --difficulty easy --count 5", "study -d medium -c 3", "study --company meta -c 2", "study -c 3 --mark 1:2 --mark 3:1"},
+			Flags: func() []*flag.FlagSet {
+				return []*flag.FlagSet{studyFlags(&studyOptions{})}
+			},
 			Callback: func(args []string) error {
 				return studyCommandWithDB(db, args)
 			},
@@ -216,6 +530,11 @@ func getCommands(db *sql.DB) map[string]CliCommand {
 		"review": {
 			Name:        "review",
 			Description: "View your review history and upcoming reviews",
+			Usage:       "review [-d difficulty] [--tag tag] [--topic topic] [--status status] [--sort order] [--limit n [--page p]]",
+			Examples:    []string{"review", "review -d hard --tag graphs", "review --status overdue --sort ef", "review --topic graph --limit 10 --page 2"},
+			Flags: func() []*flag.FlagSet {
+				return []*flag.FlagSet{reviewFlags(&reviewOptions{})}
+			},
 			Callback: func(args []string) error {
 				return reviewCommandWithDB(db, args)
 			},
@@ -223,9 +542,253 @@ func getCommands(db *sql.DB) map[string]CliCommand {
 		"stat": {
 			Name:        "stat",
 			Description: "View your overall study statistics",
+			Usage:       "stat [--days n] [--tag tag]",
+			Examples:    []string{"stat", "stat --days 30"},
+			Flags: func() []*flag.FlagSet {
+				return []*flag.FlagSet{statFlags(&statOptions{})}
+			},
 			Callback: func(args []string) error {
 				return statCommandWithDB(db, args)
 			},
 		},
+		"show": {
+			Name:        "show",
+			Description: "Show details and history for one problem (title or LC number)",
+			Usage:       "show <title|LC number>",
+			Examples:    []string{"show two sum", "show 1"},
+			Callback: func(args []string) error {
+				return showCommandWithDB(db, args)
+			},
+		},
+		"search": {
+			Name:        "search",
+			Description: "Search problems by title, number, topic or notes",
+			Usage:       "search <query>",
+			Examples:    []string{"search sliding window", "search 167"},
+			Callback: func(args []string) error {
+				return searchCommandWithDB(db, args)
+			},
+		},
+		"done": {
+			Name:        "done",
+			Description: "Log a completion for any problem, e.g. done two sum --rating 2",
+			Usage:       "done <title|LC number> --rating 1|2|3 [--hints n] [--date YYYY-MM-DD]",
+			Examples:    []string{"done 1 --rating 2", "done \"two sum\" -r 1 --date 2026-10-10"},
+			Flags: func() []*flag.FlagSet {
+				return []*flag.FlagSet{doneFlags(&doneOptions{})}
+			},
+			Callback: func(args []string) error {
+				return doneCommandWithDB(db, args)
+			},
+		},
+		"suspend": {
+			Name:        "suspend",
+			Description: "Take a problem out of rotation indefinitely",
+			Usage:       "suspend <title|LC number>",
+			Examples:    []string{"suspend 1"},
+			Callback: setAsideCommand(db, "suspend", "suspended", func(db *sql.DB, id int) error {
+				return setProblemState(db, id, stateSuspended)
+			}),
+		},
+		"bury": {
+			Name:        "bury",
+			Description: "Hide a problem from study until tomorrow",
+			Usage:       "bury <title|LC number>",
+			Examples:    []string{"bury two sum"},
+			Callback:    setAsideCommand(db, "bury", "buried until tomorrow", buryProblem),
+		},
+		"retire": {
+			Name:        "retire",
+			Description: "Mark a problem as mastered so it stops appearing",
+			Usage:       "retire <title|LC number>",
+			Examples:    []string{"retire 1"},
+			Callback: setAsideCommand(db, "retire", "retired", func(db *sql.DB, id int) error {
+				return setProblemState(db, id, stateRetired)
+			}),
+		},
+		"unsuspend": {
+			Name:        "unsuspend",
+			Description: "Return a suspended, buried or retired problem to rotation",
+			Usage:       "unsuspend <title|LC number>",
+			Examples:    []string{"unsuspend 1"},
+			Callback: setAsideCommand(db, "unsuspend", "is back in rotation", func(db *sql.DB, id int) error {
+				return setProblemState(db, id, stateActive)
+			}),
+		},
+		"suspended": {
+			Name:        "suspended",
+			Description: "List suspended, buried and retired problems",
+			Usage:       "suspended",
+			Callback: func(args []string) error {
+				return suspendedCommandWithDB(db, args)
+			},
+		},
+		"open": {
+			Name:        "open",
+			Description: "Open a problem in your browser (open <n> from the last list, or a title/LC number)",
+			Usage:       "open <n>\nopen <title|LC number>\nopen --set-opener <command>",
+			Examples:    []string{"open 2", "open two sum", "open --set-opener none", "open --set-opener \"w3m %s\""},
+			Flags: func() []*flag.FlagSet {
+				return []*flag.FlagSet{openFlags(new(string))}
+			},
+			Callback: func(args []string) error {
+				return openCommandWithDB(db, args)
+			},
+		},
+		"statement": {
+			Name:        "statement",
+			Description: "Read a problem's offline statement, or import statements from a JSON/markdown bundle",
+			Usage:       "statement <title|LC number>\nstatement import <file.json|file.md|dir>\nstatement --remove <title|LC number>",
+			Examples:    []string{"statement two sum", "statement import bundle.md", "statement --remove 19"},
+			Flags: func() []*flag.FlagSet {
+				var remove bool
+				return []*flag.FlagSet{statementFlags(&remove)}
+			},
+			Callback: func(args []string) error {
+				return statementCommandWithDB(db, args)
+			},
+		},
+		"hint": {
+			Name:        "hint",
+			Description: "Manage a problem's hint ladder (pattern, data structure, approach); reveal them in study with h",
+			Usage:       "hint set <problem> [--pattern text] [--structure text] [--approach text]\nhint show <problem>\nhint clear <problem>",
+			Examples:    []string{"hint set two sum --pattern \"hash map\"", "hint show 1"},
+			Flags: func() []*flag.FlagSet {
+				return []*flag.FlagSet{hintSetFlags(make([]string, len(hintLevels)))}
+			},
+			Callback: func(args []string) error {
+				return hintCommandWithDB(db, args)
+			},
+		},
+		"related": {
+			Name:        "related",
+			Description: "List problems related to one, or link/unlink two problems",
+			Usage:       "related <problem>\nrelated add|remove <problem> <problem>",
+			Examples:    []string{"related 1", "related add \"two sum\" 167"},
+			Callback: func(args []string) error {
+				return relatedCommandWithDB(db, args)
+			},
+		},
+		"drill": {
+			Name:        "drill",
+			Description: "Pattern-recognition flashcards: name the topic (or --by tag) for each problem; 'drill stats' for accuracy",
+			Usage:       "drill [-c n] [-d difficulty] [--tag tag] [--by topic|tag]\ndrill stats",
+			Examples:    []string{"drill", "drill --by tag -c 20", "drill stats"},
+			Flags: func() []*flag.FlagSet {
+				return []*flag.FlagSet{drillFlags(&drillOptions{})}
+			},
+			Callback: func(args []string) error {
+				return drillCommandWithDB(db, args)
+			},
+		},
+		"quiz": {
+			Name:        "quiz",
+			Description: "Complexity quiz cards: name a problem's optimal time/space, scheduled separately from solves",
+			Usage:       "quiz [-c n] [-d difficulty] [--tag tag]\nquiz set <problem> --time <O(..)> --space <O(..)>\nquiz clear <problem>",
+			Examples:    []string{"quiz", "quiz set two sum --time \"O(n)\" --space \"O(n)\""},
+			Flags: func() []*flag.FlagSet {
+				return []*flag.FlagSet{quizFlags(&quizOptions{}), quizSetFlags(&Complexity{})}
+			},
+			Callback: func(args []string) error {
+				return quizCommandWithDB(db, args)
+			},
+		},
+		"dash": {
+			Name:        "dash",
+			Description: "Full-screen dashboard: today's queue, problem detail, stats and activity heatmap",
+			Usage:       "dash [-c n] [-d difficulty] [--tag tag]",
+			Examples:    []string{"dash", "dash -c 20 -d medium"},
+			Flags: func() []*flag.FlagSet {
+				return []*flag.FlagSet{dashboardFlags(&ProblemFilter{}, new(int))}
+			},
+			Callback: func(args []string) error {
+				return dashboardCommandWithDB(db, args)
+			},
+		},
+		"alias": {
+			Name:        "alias",
+			Description: "Define a shortcut for a command (alias s \"study -d m -c 3\"), list them, or alias remove <name>",
+			Usage:       "alias [list]\nalias <name> \"<command>\"\nalias remove <name>",
+			Examples:    []string{"alias s \"study -d m -c 3\"", "alias remove s"},
+			Callback: func(args []string) error {
+				return aliasCommandWithDB(db, args)
+			},
+		},
+		"config": {
+			Name:        "config",
+			Description: "Show or change settings in config.json (config set study.count 3), optionally per profile",
+			Usage:       "config [list] [--profile name]\nconfig get [--profile name] <key>\nconfig set [--profile name] <key> <value>\nconfig unset [--profile name] <key>",
+			Examples:    []string{"config list", "config set study.count 3", "config set --profile interview study.difficulty medium", "config unset review.soon_days"},
+			Flags: func() []*flag.FlagSet {
+				var profile string
+				return []*flag.FlagSet{configFlags(&profile)}
+			},
+			Callback: func(args []string) error {
+				return configCommand(args)
+			},
+		},
+		"macro": {
+			Name:        "macro",
+			Description: "Define a name for several commands run in order (macro morning \"study -c 3; stat\")",
+			Usage:       "macro [list]\nmacro <name> \"<command>; <command>; ...\"\nmacro remove <name>",
+			Examples:    []string{"macro morning \"study -c 3; stat\""},
+			Callback: func(args []string) error {
+				return macroCommandWithDB(db, args)
+			},
+		},
+		"problem": {
+			Name:        "problem",
+			Description: "Add, edit or delete problems (problem add --title \"Name\" -d medium)",
+			Usage:       "problem add --title <title> --difficulty <d> [flags]\nproblem edit <problem> [flags]\nproblem delete <problem> [--force]",
+			Examples:    []string{"problem add --title \"Robot Sim\" -d medium --source internal", "problem edit 1 --grouping Hashing", "problem delete \"Robot Sim\" --force"},
+			Flags: func() []*flag.FlagSet {
+				return []*flag.FlagSet{problemFlags("problem add/edit", &Problem{Source: sourceLeetCode}), problemDeleteFlags(new(bool))}
+			},
+			Callback: func(args []string) error {
+				return problemCommandWithDB(db, args)
+			},
+		},
+		"tag": {
+			Name:        "tag",
+			Description: "Add, remove or list problem tags (tag add <tag> <problem>)",
+			Usage:       "tag add|remove <tag> <title|LC number>\ntag list [title|LC number]",
+			Examples:    []string{"tag add asked-at-google two sum", "tag list"},
+			Callback: func(args []string) error {
+				return tagCommandWithDB(db, args)
+			},
+		},
+		"company": {
+			Name:        "company",
+			Description: "Import company frequency data from CSV or list it",
+			Usage:       "company import <file.csv>\ncompany list [title|LC number]",
+			Examples:    []string{"company import meta.csv", "company list two sum"},
+			Callback: func(args []string) error {
+				return companyCommandWithDB(db, args)
+			},
+		},
+		"export": {
+			Name:        "export",
+			Description: "Export problems and progress as JSON or CSV",
+			Usage:       "export [--format json|csv] [--out file] [-d difficulty] [--tag tag]",
+			Examples:    []string{"export --format csv --out progress.csv"},
+			Flags: func() []*flag.FlagSet {
+				return []*flag.FlagSet{exportFlags(&exportOptions{})}
+			},
+			Callback: func(args []string) error {
+				return exportCommandWithDB(db, args)
+			},
+		},
+		"plan": {
+			Name:        "plan",
+			Description: "Set an interview date and see the pace needed to be ready",
+			Usage:       "plan [--date YYYY-MM-DD] [--clear]",
+			Examples:    []string{"plan --date 2026-12-01", "plan"},
+			Flags: func() []*flag.FlagSet {
+				return []*flag.FlagSet{planFlags(&planOptions{})}
+			},
+			Callback: func(args []string) error {
+				return planCommandWithDB(db, args)
+			},
+		},
 	}
 }
@@ -0,0 +1,370 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ==================== Companies ====================
+
+type CompanyCount struct {
+	Name  string
+	Count int
+}
+
+func normalizeCompany(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+// companyWeight is how strongly study --company favors a problem.
+func companyWeight(c CompanyTag) float64 {
+	return max(c.Frequency, 0.1) * (1 + c.Recency)
+}
+
+func upsertProblemCompany(db execer, problemID int, c CompanyTag) error {
+	name := normalizeCompany(c.Name)
+	if name == "" {
+		return fmt.Errorf("company name cannot be empty")
+	}
+	if c.Frequency < 0 || c.Frequency > 100 {
+		return fmt.Errorf("frequency for %s must be between 0 and 100", name)
+	}
+	if c.Recency < 0 || c.Recency > 1 {
+		return fmt.Errorf("recency for %s must be between 0 and 1", name)
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO problem_companies (problem_id, company, frequency, recency)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(problem_id, company) DO UPDATE SET
+			frequency = excluded.frequency,
+			recency = excluded.recency
+	`, problemID, name, c.Frequency, c.Recency)
+	if err != nil {
+		return fmt.Errorf("save company %s: %w", name, err)
+	}
+	return nil
+}
+
+func getProblemCompanies(db *sql.DB, problemID int) ([]CompanyTag, error) {
+	all, err := queryProblemCompanies(db, "WHERE problem_id = ?", problemID)
+	if err != nil {
+		return nil, err
+	}
+	return all[problemID], nil
+}
+
+// getAllProblemCompanies maps problem IDs to their companies, most frequent first.
+func getAllProblemCompanies(db *sql.DB) (map[int][]CompanyTag, error) {
+	return queryProblemCompanies(db, "")
+}
+
+func queryProblemCompanies(db *sql.DB, where string, args ...any) (map[int][]CompanyTag, error) {
+	rows, err := db.Query(`
+		SELECT problem_id, company, frequency, recency
+		FROM problem_companies
+		`+where+`
+		ORDER BY frequency DESC, company
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query companies: %w", err)
+	}
+	defer rows.Close()
+
+	companies := map[int][]CompanyTag{}
+	for rows.Next() {
+		var id int
+		var c CompanyTag
+		if err := rows.Scan(&id, &c.Name, &c.Frequency, &c.Recency); err != nil {
+			return nil, fmt.Errorf("scan company: %w", err)
+		}
+		companies[id] = append(companies[id], c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate rows: %w", err)
+	}
+	return companies, nil
+}
+
+func getCompanyCounts(db *sql.DB) ([]CompanyCount, error) {
+	rows, err := db.Query(`
+		SELECT company, COUNT(*)
+		FROM problem_companies
+		GROUP BY company
+		ORDER BY COUNT(*) DESC, company
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query companies: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []CompanyCount
+	for rows.Next() {
+		var c CompanyCount
+		if err := rows.Scan(&c.Name, &c.Count); err != nil {
+			return nil, fmt.Errorf("scan company: %w", err)
+		}
+		counts = append(counts, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate rows: %w", err)
+	}
+	return counts, nil
+}
+
+// selectCompanyStudyProblems keeps buildStudyQuery's order of due reviews
+// (oldest first), new problems, then upcoming reviews (nearest first), but
+// picks among the new problems with probability proportional to
+// companyWeight.
+func selectCompanyStudyProblems(db *sql.DB, filter ProblemFilter, count int) ([]Problem, error) {
+	clause, args := filter.where()
+	rows, err := db.Query(`
+		SELECT `+problemColumns+`,
+			CASE
+				WHEN date(c.next_review_date) <= date('now') THEN 1
+				WHEN c.next_review_date IS NULL THEN 2
+				ELSE 3
+			END,
+			COALESCE(c.next_review_date, ''),
+			pc.frequency,
+			pc.recency
+		FROM problems p
+		LEFT JOIN (
+			SELECT problem_id, MAX(completed_at) as last_completion, next_review_date
+			FROM completions
+			GROUP BY problem_id
+		) c ON p.id = c.problem_id
+		INNER JOIN problem_companies pc ON pc.problem_id = p.id AND pc.company = ?
+		WHERE `+eligibleProblemFilter+clause,
+		append([]any{normalizeCompany(filter.Company)}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type candidate struct {
+		problem    Problem
+		bucket     int
+		nextReview string
+		key        float64
+	}
+	var candidates []candidate
+	for rows.Next() {
+		var c candidate
+		var company CompanyTag
+		var err error
+		c.problem, err = scanProblem(rows, &c.bucket, &c.nextReview, &company.Frequency, &company.Recency)
+		if err != nil {
+			return nil, fmt.Errorf("scan problem: %w", err)
+		}
+		// Efraimidis-Spirakis: sorting by u^(1/w) samples proportionally to w
+		c.key = math.Pow(rand.Float64(), 1/companyWeight(company))
+		candidates = append(candidates, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate rows: %w", err)
+	}
+
+	slices.SortFunc(candidates, func(a, b candidate) int {
+		if a.bucket != b.bucket {
+			return a.bucket - b.bucket
+		}
+		if a.bucket != 2 {
+			return strings.Compare(a.nextReview, b.nextReview)
+		}
+		switch {
+		case a.key > b.key:
+			return -1
+		case a.key < b.key:
+			return 1
+		}
+		return 0
+	})
+
+	var problems []Problem
+	for _, c := range candidates[:min(count, len(candidates))] {
+		problems = append(problems, c.problem)
+	}
+	return problems, nil
+}
+
+// importCompanyCSV reads rows of problem,company[,frequency[,recency]] where
+// problem is an exact title or LeetCode number. A header row naming those
+// columns is required. Rows that don't match a problem are returned as skipped.
+func importCompanyCSV(db *sql.DB, r io.Reader) (imported int, skipped []string, err error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return 0, nil, fmt.Errorf("read header: %w", err)
+	}
+	cols := map[string]int{}
+	for i, name := range header {
+		cols[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	problemCol, ok := cols["problem"]
+	if !ok {
+		if problemCol, ok = cols["title"]; !ok {
+			problemCol, ok = cols["leetcode_number"]
+		}
+	}
+	companyCol, hasCompany := cols["company"]
+	if !ok || !hasCompany {
+		return 0, nil, fmt.Errorf("header must include a problem (or title/leetcode_number) column and a company column")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	field := func(record []string, name string) string {
+		if i, ok := cols[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	parseScore := func(record []string, name string) (float64, error) {
+		v := field(record, name)
+		if v == "" {
+			return 0, nil
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s %q", name, v)
+		}
+		return f, nil
+	}
+
+	line := 1
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line++
+		if err != nil {
+			return 0, nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if problemCol >= len(record) || companyCol >= len(record) {
+			return 0, nil, fmt.Errorf("line %d: missing columns", line)
+		}
+
+		key := strings.TrimSpace(record[problemCol])
+		problem, found, err := lookupProblemExact(tx, key)
+		if err != nil {
+			return 0, nil, err
+		}
+		if !found {
+			skipped = append(skipped, key)
+			continue
+		}
+
+		c := CompanyTag{Name: record[companyCol]}
+		if c.Frequency, err = parseScore(record, "frequency"); err != nil {
+			return 0, nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if c.Recency, err = parseScore(record, "recency"); err != nil {
+			return 0, nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if err := upsertProblemCompany(tx, problem.ID, c); err != nil {
+			return 0, nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		imported++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, nil, fmt.Errorf("commit transaction: %w", err)
+	}
+	return imported, skipped, nil
+}
+
+func companyCommandWithDB(db *sql.DB, args []string) error {
+	usage := fmt.Errorf("usage: company import <file.csv>, or company list [title|LC number]")
+	if len(args) == 0 {
+		return usage
+	}
+
+	switch args[0] {
+	case "import":
+		if len(args) != 2 {
+			return usage
+		}
+		f, err := os.Open(args[1])
+		if err != nil {
+			return fmt.Errorf("open %s: %w", args[1], err)
+		}
+		defer f.Close()
+
+		imported, skipped, err := importCompanyCSV(db, f)
+		if err != nil {
+			return fmt.Errorf("import %s: %w", args[1], err)
+		}
+		fmt.Println(success("Imported %d company entries", imported))
+		if len(skipped) > 0 {
+			fmt.Printf("%sSkipped %d rows with unknown problems: %s\n", theme.icon("ℹ"), len(skipped), strings.Join(skipped, ", "))
+		}
+		return nil
+
+	case "list", "ls":
+		if len(args) > 1 {
+			problem, err := resolveProblem(db, strings.Join(args[1:], " "))
+			if err != nil {
+				return err
+			}
+			companies, err := getProblemCompanies(db, problem.ID)
+			if err != nil {
+				return err
+			}
+			if len(companies) == 0 {
+				fmt.Printf("'%s' has no company data.\n", problem.Title)
+				return nil
+			}
+			fmt.Printf("\n%s%s:\n", theme.icon("🏢"), problem.Title)
+			table := Table{
+				Indent: "  ",
+				Columns: []Column{
+					{Header: "Company", Flex: true, Min: 10},
+					{Header: "Frequency", Right: true},
+					{Header: "Recency", Right: true},
+				},
+			}
+			for _, c := range companies {
+				table.AddRow(c.Name, fmt.Sprintf("%.1f", c.Frequency), fmt.Sprintf("%.2f", c.Recency))
+			}
+			table.Print()
+			fmt.Println()
+			return nil
+		}
+
+		counts, err := getCompanyCounts(db)
+		if err != nil {
+			return err
+		}
+		if len(counts) == 0 {
+			fmt.Println("\nNo company data yet. Import some with 'company import <file.csv>'.")
+			return nil
+		}
+		table := countTable("\n" + theme.icon("🏢") + "Companies:")
+		for _, c := range counts {
+			table.AddRow(c.Name, strconv.Itoa(c.Count), "problems")
+		}
+		table.Print()
+		fmt.Println()
+		return nil
+	}
+
+	return usage
+}
@@ -0,0 +1,466 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ==================== Config ====================
+
+const configFile = "config.json"
+
+// Config holds user preferences kept in config.json in the data directory,
+// next to the database. A missing file means the defaults.
+type Config struct {
+	Aliases  map[string]string         `json:"aliases,omitempty"`  // Name -> command line
+	Macros   map[string][]string       `json:"macros,omitempty"`   // Name -> command lines, run in order
+	Profile  string                    `json:"profile,omitempty"`  // Profile used unless --profile picks another
+	Settings map[string]any            `json:"settings,omitempty"` // Key -> value; see settingSpecs
+	Profiles map[string]map[string]any `json:"profiles,omitempty"` // Name -> settings overriding the ones above
+}
+
+// config is loaded once at startup by loadConfig.
+var config = &Config{}
+
+func configPath() (string, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, configFile), nil
+}
+
+func loadConfig() error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+
+	var c Config
+	if err := json.Unmarshal(data, &c); err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+	if err := validateConfig(&c); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	config = &c
+	return nil
+}
+
+// saveConfig writes the config back, replacing the file atomically so a
+// failed write never leaves it half-written.
+func saveConfig() error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode config: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+	return nil
+}
+
+// ==================== Settings ====================
+
+// Settings are the tunables in config.json's "settings", with a profile's
+// "profiles" entry laid over them.
+type Settings struct {
+	StudyDifficulty      string  `json:"study.difficulty"`
+	StudyCount           int     `json:"study.count"`
+	SoonDays             int     `json:"review.soon_days"`
+	ProblemsPerDay       int     `json:"stats.problems_per_day"`
+	Theme                string  `json:"theme"`
+	InitialEF            float64 `json:"sm2.initial_ef"`
+	MinEF                float64 `json:"sm2.min_ef"`
+	FirstIntervalEasy    int     `json:"sm2.first_interval_easy"`
+	FirstIntervalMedium  int     `json:"sm2.first_interval_medium"`
+	SecondIntervalEasy   int     `json:"sm2.second_interval_easy"`
+	SecondIntervalMedium int     `json:"sm2.second_interval_medium"`
+}
+
+var defaultSettings = Settings{
+	StudyDifficulty:      "any",
+	StudyCount:           1,
+	SoonDays:             3,
+	ProblemsPerDay:       3,
+	Theme:                themeColor,
+	InitialEF:            2.5,
+	MinEF:                1.3,
+	FirstIntervalEasy:    4,
+	FirstIntervalMedium:  2,
+	SecondIntervalEasy:   14,
+	SecondIntervalMedium: 7,
+}
+
+// settings are the effective settings, set up by applySettings.
+var settings = defaultSettings
+
+// activeProfile is the profile chosen with --profile or "profile" in
+// config.json, or "" for none.
+var activeProfile string
+
+// themeFlag is --theme, which wins over the theme setting.
+var themeFlag string
+
+type settingSpec struct {
+	Key         string
+	Description string
+	check       func(v any) error
+}
+
+// settingSpecs lists every setting 'config' accepts, in the order 'config
+// list' shows them. Keys match the json tags on Settings.
+var settingSpecs = []settingSpec{
+	{"study.difficulty", "Difficulty study picks from unless -d is given", oneOf("easy", "medium", "hard", "any", "e", "m", "h", "a")},
+	{"study.count", "Problems study lists unless -c is given", intBetween(1, 50)},
+	{"review.soon_days", "Days ahead a review counts as due soon", intBetween(1, 30)},
+	{"stats.problems_per_day", "Pace stat assumes when there's no recent history", intBetween(1, 100)},
+	{"theme", "Output theme: " + strings.Join(themeNames, ", "), oneOf(themeNames...)},
+	{"sm2.initial_ef", "Easiness factor of a problem never solved before", floatBetween(1.3, 5)},
+	{"sm2.min_ef", "Lowest the easiness factor can fall", floatBetween(1.1, 2.5)},
+	{"sm2.first_interval_easy", "Days until the first review after an Easy solve", intBetween(1, 365)},
+	{"sm2.first_interval_medium", "Days until the first review after a Medium solve", intBetween(1, 365)},
+	{"sm2.second_interval_easy", "Days until the second review after an Easy solve", intBetween(1, 365)},
+	{"sm2.second_interval_medium", "Days until the second review after a Medium solve", intBetween(1, 365)},
+}
+
+func oneOf(choices ...string) func(any) error {
+	return func(v any) error {
+		s, ok := v.(string)
+		if !ok || !slices.Contains(choices, strings.ToLower(s)) {
+			return fmt.Errorf("must be one of %s", strings.Join(choices, ", "))
+		}
+		return nil
+	}
+}
+
+func intBetween(lo, hi int) func(any) error {
+	return func(v any) error {
+		f, ok := v.(float64)
+		if !ok || f != math.Trunc(f) || f < float64(lo) || f > float64(hi) {
+			return fmt.Errorf("must be a whole number from %d to %d", lo, hi)
+		}
+		return nil
+	}
+}
+
+func floatBetween(lo, hi float64) func(any) error {
+	return func(v any) error {
+		f, ok := v.(float64)
+		if !ok || f < lo || f > hi {
+			return fmt.Errorf("must be a number from %g to %g", lo, hi)
+		}
+		return nil
+	}
+}
+
+func findSetting(key string) (settingSpec, error) {
+	for _, spec := range settingSpecs {
+		if spec.Key == key {
+			return spec, nil
+		}
+	}
+	return settingSpec{}, fmt.Errorf("unknown setting '%s'; see 'config list'", key)
+}
+
+// validateSettings checks raw settings as decoded from JSON, where numbers
+// are float64.
+func validateSettings(raw map[string]any) error {
+	for _, key := range slices.Sorted(maps.Keys(raw)) {
+		spec, err := findSetting(key)
+		if err != nil {
+			return err
+		}
+		if err := spec.check(raw[key]); err != nil {
+			return fmt.Errorf("%s %w", key, err)
+		}
+	}
+	return nil
+}
+
+// validateConfig checks everything loadConfig can't catch while decoding.
+func validateConfig(c *Config) error {
+	if err := validateSettings(c.Settings); err != nil {
+		return fmt.Errorf("settings: %w", err)
+	}
+	for _, name := range slices.Sorted(maps.Keys(c.Profiles)) {
+		if err := validateSettings(c.Profiles[name]); err != nil {
+			return fmt.Errorf("profile %s: %w", name, err)
+		}
+	}
+	if c.Profile != "" {
+		if _, ok := c.Profiles[c.Profile]; !ok {
+			return fmt.Errorf("profile %q is selected but not defined", c.Profile)
+		}
+	}
+	return nil
+}
+
+// resolveSettings lays the config's settings, then the profile's, over the
+// defaults.
+func resolveSettings(c *Config, profile string) (Settings, error) {
+	s := defaultSettings
+	for _, raw := range []map[string]any{c.Settings, c.Profiles[profile]} {
+		if len(raw) == 0 {
+			continue
+		}
+		data, err := json.Marshal(raw)
+		if err != nil {
+			return s, fmt.Errorf("encode settings: %w", err)
+		}
+		if err := json.Unmarshal(data, &s); err != nil {
+			return s, fmt.Errorf("decode settings: %w", err)
+		}
+	}
+	if long, ok := shortToLong[strings.ToLower(s.StudyDifficulty)]; ok {
+		s.StudyDifficulty = long
+	}
+	if s.MinEF > s.InitialEF {
+		return s, fmt.Errorf("sm2.min_ef (%g) is above sm2.initial_ef (%g)", s.MinEF, s.InitialEF)
+	}
+	return s, nil
+}
+
+// applySettings makes the config's settings, under the active profile, take
+// effect.
+func applySettings() error {
+	if activeProfile != "" {
+		if _, ok := config.Profiles[activeProfile]; !ok {
+			return fmt.Errorf("no profile named '%s' in %s", activeProfile, configFile)
+		}
+	}
+	s, err := resolveSettings(config, activeProfile)
+	if err != nil {
+		return err
+	}
+	settings = s
+
+	if themeFlag != "" {
+		return setTheme(themeFlag, true)
+	}
+	return setTheme(settings.Theme, false)
+}
+
+// settingValue reads one effective setting by key.
+func settingValue(s Settings, key string) any {
+	data, _ := json.Marshal(s)
+	var values map[string]any
+	json.Unmarshal(data, &values)
+	return values[key]
+}
+
+// parseSettingValue turns a value typed on the command line into the JSON
+// type its setting holds.
+func parseSettingValue(spec settingSpec, input string) (any, error) {
+	var v any = strings.ToLower(input)
+	if _, isNumber := settingValue(defaultSettings, spec.Key).(float64); isNumber {
+		f, err := strconv.ParseFloat(input, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a number", spec.Key)
+		}
+		v = f
+	}
+	if err := spec.check(v); err != nil {
+		return nil, fmt.Errorf("%s %w", spec.Key, err)
+	}
+	return v, nil
+}
+
+// settingSource says where an effective setting comes from.
+func settingSource(key, profile string) string {
+	if _, ok := config.Profiles[profile][key]; ok {
+		return "profile " + profile
+	}
+	if _, ok := config.Settings[key]; ok {
+		return configFile
+	}
+	return "default"
+}
+
+func configFlags(profile *string) *flag.FlagSet {
+	fs := newFlagSet("config")
+	fs.StringVar(profile, "profile", "", "Read or change this profile instead of the base settings")
+	fs.StringVar(profile, "p", "", "Short for profile")
+	return fs
+}
+
+func configCommand(args []string) error {
+	usage := fmt.Errorf("usage: config [list], config get <key>, config set <key> <value> or config unset <key>, each optionally with --profile <name> before the key")
+
+	action := "list"
+	if len(args) > 0 {
+		action, args = args[0], args[1:]
+	}
+	var profile string
+	fs := configFlags(&profile)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	positional := fs.Args()
+
+	// Reading shows what would apply under the profile; without --profile,
+	// that's the active one
+	view := profile
+	if view == "" {
+		view = activeProfile
+	}
+	if _, ok := config.Profiles[view]; view != "" && !ok && action != "set" {
+		return fmt.Errorf("no profile named '%s'", view)
+	}
+
+	switch action {
+	case "list":
+		if len(positional) != 0 {
+			return usage
+		}
+		effective, err := resolveSettings(config, view)
+		if err != nil {
+			return err
+		}
+		title := "\nSettings:"
+		if view != "" {
+			title = fmt.Sprintf("\nSettings (profile %s):", view)
+		}
+		table := Table{
+			Title: title,
+			Columns: []Column{
+				{Header: "Key"},
+				{Header: "Value"},
+				{Header: "From"},
+				{Header: "Description", Flex: true, Min: 20},
+			},
+		}
+		for _, spec := range settingSpecs {
+			table.AddRow(spec.Key, fmt.Sprint(settingValue(effective, spec.Key)), settingSource(spec.Key, view), spec.Description)
+		}
+		table.Print()
+		if len(config.Profiles) > 0 {
+			fmt.Printf("\nProfiles: %s\n", strings.Join(slices.Sorted(maps.Keys(config.Profiles)), ", "))
+		}
+		fmt.Println()
+		return nil
+
+	case "get":
+		if len(positional) != 1 {
+			return usage
+		}
+		if _, err := findSetting(positional[0]); err != nil {
+			return err
+		}
+		effective, err := resolveSettings(config, view)
+		if err != nil {
+			return err
+		}
+		fmt.Println(settingValue(effective, positional[0]))
+		return nil
+
+	case "set", "unset":
+		if (action == "set" && len(positional) != 2) || (action == "unset" && len(positional) != 1) {
+			return usage
+		}
+		key := positional[0]
+		spec, err := findSetting(key)
+		if err != nil {
+			return err
+		}
+
+		// Change a copy so a rejected value leaves the config as it was
+		updated := *config
+		updated.Settings = maps.Clone(config.Settings)
+		updated.Profiles = maps.Clone(config.Profiles)
+		target := &updated.Settings
+		if profile != "" {
+			if updated.Profiles == nil {
+				updated.Profiles = map[string]map[string]any{}
+			}
+			raw := maps.Clone(updated.Profiles[profile])
+			updated.Profiles[profile] = raw
+			target = &raw
+		}
+
+		if action == "set" {
+			value, err := parseSettingValue(spec, positional[1])
+			if err != nil {
+				return err
+			}
+			if *target == nil {
+				*target = map[string]any{}
+			}
+			(*target)[key] = value
+		} else {
+			if _, ok := (*target)[key]; !ok {
+				return fmt.Errorf("%s isn't set there; it's already the default", key)
+			}
+			delete(*target, key)
+		}
+		if profile != "" {
+			updated.Profiles[profile] = *target
+		}
+		// A base setting can clash with any profile's, so check them all
+		// before saving something that would fail to load
+		if err := validateConfig(&updated); err != nil {
+			return err
+		}
+		for _, name := range append([]string{""}, slices.Sorted(maps.Keys(updated.Profiles))...) {
+			if _, err := resolveSettings(&updated, name); err != nil {
+				if name != "" {
+					return fmt.Errorf("profile %s: %w", name, err)
+				}
+				return err
+			}
+		}
+
+		previous := config
+		config = &updated
+		if err := saveConfig(); err != nil {
+			config = previous
+			return err
+		}
+		if err := applySettings(); err != nil {
+			return err
+		}
+
+		where := ""
+		if profile != "" {
+			where = fmt.Sprintf(" in profile %s", profile)
+		}
+		if action == "set" {
+			fmt.Println(success("Set %s to %v%s", key, (*target)[key], where))
+		} else {
+			fmt.Println(success("Reset %s%s", key, where))
+		}
+		if _, overridden := config.Profiles[activeProfile][key]; profile == "" && overridden {
+			fmt.Printf("Profile %s, which is in use, sets its own %s.\n", activeProfile, key)
+		}
+		return nil
+	}
+
+	return usage
+}
@@ -0,0 +1,543 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// ==================== Dashboard ====================
+
+const (
+	enterAltScreen = "\033[?1049h\033[?25l"
+	leaveAltScreen = "\033[?25h\033[?1049l"
+
+	heatmapWeeks     = 12
+	statsPaneHeight  = 9 // Seven heatmap rows plus the border
+	minDashboardSize = 20
+)
+
+// heatmapShades goes from no completions on a day to four or more.
+var heatmapShades = []string{"·", "░", "▒", "▓", "█"}
+
+// dashboard is the state behind the full-screen view. Everything shown is
+// loaded by reload, so each pane renders from memory.
+type dashboard struct {
+	db     *sql.DB
+	filter ProblemFilter
+	count  int
+
+	mu       sync.Mutex // Held while rendering or changing state
+	queue    []Problem
+	selected int
+	reviews  map[string]ReviewInfo
+	stats    *OverallStats
+	daily    map[string]int
+	tags     []string
+	history  []CompletionRecord
+	status   string
+
+	// Set when problems were rated since the projection was worked out
+	projectionStale bool
+
+	// Set while typing a note: the text so far
+	editing bool
+	note    []rune
+}
+
+// getDailyCompletions counts completions per day (YYYY-MM-DD) over the last
+// days days.
+func getDailyCompletions(db *sql.DB, days int) (map[string]int, error) {
+	rows, err := db.Query(`
+		SELECT date(completed_at), COUNT(*)
+		FROM completions
+		WHERE date(completed_at) > date('now', '-' || ? || ' days')
+		GROUP BY date(completed_at)
+	`, days)
+	if err != nil {
+		return nil, fmt.Errorf("query daily completions: %w", err)
+	}
+	defer rows.Close()
+
+	daily := map[string]int{}
+	for rows.Next() {
+		var day string
+		var count int
+		if err := rows.Scan(&day, &count); err != nil {
+			return nil, fmt.Errorf("scan daily completions: %w", err)
+		}
+		daily[day] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate rows: %w", err)
+	}
+	return daily, nil
+}
+
+// reload refreshes the stats, review info and heatmap. When full is set it
+// also refreshes the queue (otherwise rated and skipped problems would come
+// back) and reruns the completion projection, which is too slow for every
+// rating.
+func (d *dashboard) reload(full bool) error {
+	var stats *OverallStats
+	var err error
+	if full {
+		queue, err := selectStudyProblems(d.db, d.filter, d.count)
+		if err != nil {
+			return err
+		}
+		d.queue = queue
+		d.selected = 0
+		if stats, err = getOverallStats(d.db, historyWindowDays, d.filter); err != nil {
+			return err
+		}
+	} else {
+		if stats, err = getStatCounts(d.db, d.filter); err != nil {
+			return err
+		}
+		stats.CompletionProjection = d.stats.CompletionProjection
+	}
+	d.projectionStale = !full
+	reviews, err := getReviewHistory(d.db, ProblemFilter{}, "next", "")
+	if err != nil {
+		return err
+	}
+	daily, err := getDailyCompletions(d.db, heatmapWeeks*7)
+	if err != nil {
+		return err
+	}
+
+	d.stats, d.daily = stats, daily
+	d.reviews = map[string]ReviewInfo{}
+	for _, r := range reviews {
+		d.reviews[r.Title] = r
+	}
+	return d.loadSelected()
+}
+
+// loadSelected loads the details only needed for the selected problem.
+func (d *dashboard) loadSelected() error {
+	d.tags, d.history = nil, nil
+	if len(d.queue) == 0 {
+		return nil
+	}
+	p := d.queue[d.selected]
+
+	var err error
+	if d.tags, err = getProblemTags(d.db, p.ID); err != nil {
+		return err
+	}
+	d.history, err = getCompletionHistory(d.db, p.ID)
+	return err
+}
+
+func (d *dashboard) move(delta int) error {
+	if len(d.queue) == 0 {
+		return nil
+	}
+	d.selected = (d.selected + delta + len(d.queue)) % len(d.queue)
+	return d.loadSelected()
+}
+
+// remove takes the selected problem off today's queue.
+func (d *dashboard) remove() {
+	d.queue = append(d.queue[:d.selected], d.queue[d.selected+1:]...)
+	if d.selected >= len(d.queue) {
+		d.selected = max(len(d.queue)-1, 0)
+	}
+}
+
+func (d *dashboard) rate(rating int) error {
+	if len(d.queue) == 0 {
+		return nil
+	}
+	p := d.queue[d.selected]
+	if err := updateProblemCompletion(d.db, p.Title, rating, 0, time.Now()); err != nil {
+		return fmt.Errorf("update problem: %w", err)
+	}
+	d.remove()
+	d.status = theme.icon("✓") + fmt.Sprintf("Marked '%s' as completed with effort rating %d", p.Title, rating)
+	return d.reload(false)
+}
+
+func (d *dashboard) saveNote() error {
+	d.editing = false
+	p := d.queue[d.selected]
+	p.Notes = strings.TrimSpace(string(d.note))
+	if err := updateProblem(d.db, p); err != nil {
+		return err
+	}
+	d.queue[d.selected] = p
+	d.status = theme.icon("✓") + fmt.Sprintf("Saved notes for '%s'", p.Title)
+	return nil
+}
+
+// handleKey applies one key press and reports whether to quit.
+func (d *dashboard) handleKey(key string) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.editing {
+		switch key {
+		case "enter":
+			return false, d.saveNote()
+		case "esc", "ctrl-c":
+			d.editing = false
+			d.status = "Note discarded"
+		case "backspace":
+			if len(d.note) > 0 {
+				d.note = d.note[:len(d.note)-1]
+			}
+		default:
+			if r, size := utf8.DecodeRuneInString(key); size == len(key) && r >= ' ' {
+				d.note = append(d.note, r)
+			}
+		}
+		return false, nil
+	}
+
+	d.status = ""
+	switch key {
+	case "q", "esc", "ctrl-c":
+		return true, nil
+	case "up", "k":
+		return false, d.move(-1)
+	case "down", "j":
+		return false, d.move(1)
+	case "1", "2", "3":
+		return false, d.rate(int(key[0] - '0'))
+	case "s":
+		if len(d.queue) > 0 {
+			d.status = fmt.Sprintf("Skipped '%s' for now", d.queue[d.selected].Title)
+			d.remove()
+			return false, d.loadSelected()
+		}
+	case "o":
+		if len(d.queue) > 0 {
+			msg, err := launchProblem(d.db, d.queue[d.selected])
+			if err != nil {
+				return false, err
+			}
+			d.status = msg
+		}
+	case "n":
+		if len(d.queue) > 0 {
+			d.editing = true
+			d.note = []rune(d.queue[d.selected].Notes)
+		}
+	case "r":
+		d.status = "Refreshed"
+		return false, d.reload(true)
+	}
+	return false, nil
+}
+
+// readKey reads one key press from the raw terminal, naming the special
+// keys the dashboard uses.
+func readKey() (string, error) {
+	b, err := stdin.ReadByte()
+	if err != nil {
+		return "", err
+	}
+	switch b {
+	case 3:
+		return "ctrl-c", nil
+	case '\r', '\n':
+		return "enter", nil
+	case 127, 8:
+		return "backspace", nil
+	case 27:
+		// A lone Escape, or the start of an arrow key sequence
+		if stdin.Buffered() == 0 {
+			return "esc", nil
+		}
+		seq := make([]byte, 2)
+		if _, err := io.ReadFull(stdin, seq); err != nil {
+			return "", err
+		}
+		switch string(seq) {
+		case "[A":
+			return "up", nil
+		case "[B":
+			return "down", nil
+		}
+		return "", nil
+	}
+
+	// Put multi-byte characters back together for note editing
+	if b < utf8.RuneSelf {
+		return string(b), nil
+	}
+	stdin.UnreadByte()
+	r, _, err := stdin.ReadRune()
+	return string(r), err
+}
+
+// fitWidth truncates or pads s to exactly width columns.
+func fitWidth(s string, width int) string {
+	return padWidth(truncateWidth(s, width), width, false)
+}
+
+// drawBox frames lines in a width x height box. The line at highlight (or none
+// when -1) is shown in reverse video.
+func drawBox(title string, lines []string, width, height, highlight int) []string {
+	inner := width - 2
+	heading := theme.symbol("─") + " " + title + " "
+	if n := displayWidth(heading); n < inner {
+		heading += theme.rule("─", inner-n)
+	}
+	heading = fitWidth(heading, inner)
+	box := []string{theme.symbol("┌") + heading + theme.symbol("┐")}
+	for i := 0; i < height-2; i++ {
+		var line string
+		if i < len(lines) {
+			line = lines[i]
+		}
+		line = fitWidth(" "+line, inner)
+		if i == highlight {
+			if theme.Color {
+				line = "\033[7m" + line + "\033[0m"
+			} else {
+				line = ">" + line[1:]
+			}
+		}
+		box = append(box, theme.symbol("│")+line+theme.symbol("│"))
+	}
+	return append(box, theme.symbol("└")+theme.rule("─", inner)+theme.symbol("┘"))
+}
+
+func (d *dashboard) queueLines() ([]string, int) {
+	if len(d.queue) == 0 {
+		return []string{"Nothing left for today!", "", "Press r to reload the queue."}, -1
+	}
+	var lines []string
+	for i, p := range d.queue {
+		lines = append(lines, fmt.Sprintf("%d. [%s] %s (%s)", i+1, p.Ref(), p.Title, p.Difficulty))
+	}
+	return lines, d.selected
+}
+
+func (d *dashboard) detailLines(width int) []string {
+	if len(d.queue) == 0 {
+		return nil
+	}
+	p := d.queue[d.selected]
+	lines := []string{
+		p.Title,
+		fmt.Sprintf("[%s] %s - %s", p.Ref(), p.Difficulty, p.Grouping),
+	}
+	if len(d.tags) > 0 {
+		lines = append(lines, "Tags: "+strings.Join(d.tags, ", "))
+	}
+	lines = append(lines, "")
+
+	if r, ok := d.reviews[p.Title]; ok {
+		_, status := getReviewStatus(r.DaysUntilReview)
+		lines = append(lines,
+			fmt.Sprintf("Last done:   %s", formatReviewDate(r.LastCompletedAt)),
+			fmt.Sprintf("Next review: %s (%s)", formatReviewDate(r.NextReviewDate), status),
+			fmt.Sprintf("Repetitions: %d   EF: %.2f", r.Repetitions.Int64, r.EasinessFactor.Float64),
+		)
+	} else {
+		lines = append(lines, "Never attempted")
+	}
+
+	if len(d.history) > 0 {
+		lines = append(lines, "", "Recent ratings:")
+		for _, c := range d.history[max(len(d.history)-3, 0):] {
+			lines = append(lines, fmt.Sprintf("  %s  %s", formatReviewDate(c.CompletedAt), ratingLabels[c.EffortRating]))
+		}
+	}
+
+	lines = append(lines, "", "Notes:")
+	if p.Notes == "" {
+		lines = append(lines, "  (none; press n to add some)")
+	} else {
+		lines = append(lines, wrapText(p.Notes, "  ", width-4)...)
+	}
+	return lines
+}
+
+func (d *dashboard) statsLines() []string {
+	s := d.stats
+	percent := func(done, total int) float64 {
+		if total == 0 {
+			return 0
+		}
+		return float64(done) / float64(total) * 100
+	}
+	lines := []string{
+		fmt.Sprintf("Completed: %d / %d (%.1f%%)", s.CompletedProblems, s.TotalProblems, percent(s.CompletedProblems, s.TotalProblems)),
+		fmt.Sprintf("Easy %d/%d   Medium %d/%d   Hard %d/%d",
+			s.EasyCompleted, s.EasyTotal, s.MediumCompleted, s.MediumTotal, s.HardCompleted, s.HardTotal),
+		fmt.Sprintf("Due: %d overdue, %d today, %d soon", s.OverdueReviews, s.DueTodayReviews, s.UpcomingReviews),
+		"",
+		"P50 done: " + formatProjection(s.P50Days, s.P50CompletionAt),
+		"P90 done: " + formatProjection(s.P90Days, s.P90CompletionAt),
+	}
+	if d.projectionStale {
+		lines = append(lines, "(as of last reload; r to update)")
+	}
+	return lines
+}
+
+// heatmapLines draws completions per day for the last heatmapWeeks weeks, one
+// column per week and one row per weekday, ending today.
+func (d *dashboard) heatmapLines() []string {
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	start := today.AddDate(0, 0, -int(today.Weekday())-(heatmapWeeks-1)*7)
+
+	days := []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+	var lines []string
+	for wd := 0; wd < 7; wd++ {
+		var b strings.Builder
+		b.WriteString(days[wd] + " ")
+		for week := 0; week < heatmapWeeks; week++ {
+			day := start.AddDate(0, 0, week*7+wd)
+			if day.After(today) {
+				b.WriteString("  ")
+				continue
+			}
+			count := d.daily[day.Format("2006-01-02")]
+			b.WriteString(theme.symbol(heatmapShades[min(count, len(heatmapShades)-1)]) + " ")
+		}
+		lines = append(lines, b.String())
+	}
+	return lines
+}
+
+// render draws the whole screen. It must be called with d.mu held.
+func (d *dashboard) render() {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width <= 0 {
+		width, height = 80, 24
+	}
+
+	var b strings.Builder
+	b.WriteString("\033[H\033[2J")
+	if width < minDashboardSize*2 || height < minDashboardSize {
+		b.WriteString("Terminal too small for the dashboard; make it bigger or press q.")
+		os.Stdout.WriteString(b.String())
+		return
+	}
+
+	leftWidth := width / 2
+	rightWidth := width - leftWidth
+	topHeight := height - statsPaneHeight - 2
+
+	queue, selected := d.queueLines()
+	// Keep the selection visible in long queues
+	offset := max(selected-(topHeight-3), 0)
+	if selected >= 0 {
+		selected -= offset
+	}
+	left := drawBox(fmt.Sprintf("Today's Queue (%d)", len(d.queue)), queue[offset:], leftWidth, topHeight, selected)
+	left = append(left, drawBox("Stats", d.statsLines(), leftWidth, statsPaneHeight, -1)...)
+	right := drawBox("Problem", d.detailLines(rightWidth), rightWidth, topHeight, -1)
+	right = append(right, drawBox(fmt.Sprintf("Activity (last %d weeks)", heatmapWeeks), d.heatmapLines(), rightWidth, statsPaneHeight, -1)...)
+
+	for i := range left {
+		b.WriteString(left[i] + right[i] + "\r\n")
+	}
+
+	switch {
+	case d.editing:
+		b.WriteString(fitWidth("Notes: "+string(d.note)+theme.symbol("▏"), width-1) + "\r\n")
+		b.WriteString(fitWidth(" Enter save   Esc cancel", width-1))
+	default:
+		b.WriteString(fitWidth(d.status, width-1) + "\r\n")
+		keys := fitWidth(" "+theme.symbol("↑")+"/"+theme.symbol("↓")+" select  1/2/3 rate  s skip  o open  n notes  r reload  q quit", width-1)
+		if theme.Color {
+			keys = "\033[2m" + keys + "\033[0m"
+		}
+		b.WriteString(keys)
+	}
+	os.Stdout.WriteString(b.String())
+}
+
+func dashboardFlags(filter *ProblemFilter, count *int) *flag.FlagSet {
+	fs := newFlagSet("dash")
+	filterFlags(fs, filter)
+	fs.IntVar(count, "count", 10, "Number of problems in today's queue")
+	fs.IntVar(count, "c", 10, "Short for count")
+	return fs
+}
+
+func dashboardCommandWithDB(db *sql.DB, args []string) error {
+	var filter ProblemFilter
+	var count int
+	if err := dashboardFlags(&filter, &count).Parse(args); err != nil {
+		return err
+	}
+	if !interactive || !isTerminal(os.Stdout) {
+		return fmt.Errorf("dash is full-screen, so it needs an interactive terminal")
+	}
+	if val, ok := shortToLong[filter.Difficulty]; ok {
+		filter.Difficulty = val
+	}
+
+	d := &dashboard{db: db, filter: filter, count: count}
+	if err := d.reload(true); err != nil {
+		return err
+	}
+
+	if err := makeRaw(leaveAltScreen); err != nil {
+		return fmt.Errorf("enter full-screen mode: %w", err)
+	}
+	defer restoreTerminal()
+	os.Stdout.WriteString(enterAltScreen)
+
+	// Redraw when the window changes size
+	resized := make(chan os.Signal, 1)
+	signal.Notify(resized, syscall.SIGWINCH)
+	defer signal.Stop(resized)
+	done, stopped := make(chan struct{}), make(chan struct{})
+	defer func() {
+		close(done)
+		<-stopped // So it can't draw after the terminal is restored
+	}()
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-resized:
+				d.mu.Lock()
+				d.render()
+				d.mu.Unlock()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	for {
+		d.mu.Lock()
+		d.render()
+		d.mu.Unlock()
+
+		key, err := readKey()
+		if err != nil {
+			return nil // Input closed
+		}
+		quit, err := d.handleKey(key)
+		if quit {
+			return nil
+		}
+		if err != nil {
+			d.mu.Lock()
+			d.status = "Error: " + err.Error()
+			d.mu.Unlock()
+		}
+	}
+}
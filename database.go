@@ -8,13 +8,21 @@ import (
 	"path/filepath"
 )
 
-func initDb() (*sql.DB, error) {
-	// Get absolute path to the executable's directory
+// dataDir is where the database, seed file and REPL history live: the
+// executable's directory.
+func dataDir() (string, error) {
 	exePath, err := os.Executable()
 	if err != nil {
-		return nil, fmt.Errorf("get executable path: %w", err)
+		return "", fmt.Errorf("get executable path: %w", err)
+	}
+	return filepath.Dir(exePath), nil
+}
+
+func initDb() (*sql.DB, error) {
+	exeDir, err := dataDir()
+	if err != nil {
+		return nil, err
 	}
-	exeDir := filepath.Dir(exePath)
 
 	// Use absolute paths for database and seed file
 	dbPath := filepath.Join(exeDir, "app.db")
@@ -41,10 +49,13 @@ func initDb() (*sql.DB, error) {
 			db.Close()
 			return nil, fmt.Errorf("failed to seed problems: %w", err)
 		}
-		fmt.Println("ℹ neetcode_150.json not found; skipping initial seed")
+		fmt.Fprintln(os.Stderr, theme.icon("ℹ")+"neetcode_150.json not found; skipping initial seed")
 	}
 
-	fmt.Println("✓ Database initialized")
+	// Scripts and pipes get only the output of the commands they ran
+	if interactive {
+		fmt.Println(success("Database initialized"))
+	}
 	return db, nil
 }
 
@@ -73,6 +84,103 @@ func createTables(db *sql.DB) error {
 			FOREIGN KEY (problem_id) REFERENCES problems(id)
 		);`
 
+	createSettingsTable := `
+		CREATE TABLE IF NOT EXISTS settings (
+			key TEXT PRIMARY KEY,
+			value TEXT NOT NULL
+		);`
+
+	createTagsTable := `
+		CREATE TABLE IF NOT EXISTS tags (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE
+		);`
+
+	createProblemTagsTable := `
+		CREATE TABLE IF NOT EXISTS problem_tags (
+			problem_id INTEGER NOT NULL,
+			tag_id INTEGER NOT NULL,
+			PRIMARY KEY (problem_id, tag_id),
+			FOREIGN KEY (problem_id) REFERENCES problems(id),
+			FOREIGN KEY (tag_id) REFERENCES tags(id)
+		);`
+
+	createProblemCompaniesTable := `
+		CREATE TABLE IF NOT EXISTS problem_companies (
+			problem_id INTEGER NOT NULL,
+			company TEXT NOT NULL,
+			frequency REAL NOT NULL DEFAULT 0,
+			recency REAL NOT NULL DEFAULT 0,
+			PRIMARY KEY (problem_id, company),
+			FOREIGN KEY (problem_id) REFERENCES problems(id)
+		);`
+
+	createStatementsTable := `
+		CREATE TABLE IF NOT EXISTS statements (
+			problem_id INTEGER PRIMARY KEY,
+			body TEXT NOT NULL,
+			examples TEXT NOT NULL DEFAULT '[]', -- JSON array of strings
+			constraints TEXT NOT NULL DEFAULT '[]',
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (problem_id) REFERENCES problems(id)
+		);`
+
+	createHintsTable := `
+		CREATE TABLE IF NOT EXISTS hints (
+			problem_id INTEGER NOT NULL,
+			level INTEGER NOT NULL,
+			text TEXT NOT NULL,
+			PRIMARY KEY (problem_id, level),
+			FOREIGN KEY (problem_id) REFERENCES problems(id)
+		);`
+
+	// Relations are stored in both directions
+	createRelatedProblemsTable := `
+		CREATE TABLE IF NOT EXISTS related_problems (
+			problem_id INTEGER NOT NULL,
+			related_id INTEGER NOT NULL,
+			PRIMARY KEY (problem_id, related_id),
+			FOREIGN KEY (problem_id) REFERENCES problems(id),
+			FOREIGN KEY (related_id) REFERENCES problems(id)
+		);`
+
+	createDrillReviewsTable := `
+		CREATE TABLE IF NOT EXISTS drill_reviews (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			problem_id INTEGER NOT NULL,
+			mode TEXT NOT NULL,
+			correct INTEGER NOT NULL,
+			box INTEGER NOT NULL,
+			answered_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			next_review_date DATETIME,
+			FOREIGN KEY (problem_id) REFERENCES problems(id)
+		);`
+
+	createComplexitiesTable := `
+		CREATE TABLE IF NOT EXISTS complexities (
+			problem_id INTEGER PRIMARY KEY,
+			time TEXT NOT NULL,
+			space TEXT NOT NULL,
+			FOREIGN KEY (problem_id) REFERENCES problems(id)
+		);`
+
+	createQuizReviewsTable := `
+		CREATE TABLE IF NOT EXISTS quiz_reviews (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			problem_id INTEGER NOT NULL,
+			time_answer TEXT NOT NULL,
+			space_answer TEXT NOT NULL,
+			time_correct INTEGER NOT NULL,
+			space_correct INTEGER NOT NULL,
+			effort_rating INTEGER NOT NULL,
+			interval_days INTEGER DEFAULT 1,
+			easiness_factor REAL DEFAULT 2.5,
+			repetitions INTEGER DEFAULT 0,
+			answered_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			next_review_date DATETIME,
+			FOREIGN KEY (problem_id) REFERENCES problems(id)
+		);`
+
 	if _, err := db.Exec(createProblemsTable); err != nil {
 		return fmt.Errorf("create problems table: %w", err)
 	}
@@ -81,5 +189,82 @@ func createTables(db *sql.DB) error {
 		return fmt.Errorf("create completions table: %w", err)
 	}
 
+	if _, err := db.Exec(createSettingsTable); err != nil {
+		return fmt.Errorf("create settings table: %w", err)
+	}
+
+	if _, err := db.Exec(createTagsTable); err != nil {
+		return fmt.Errorf("create tags table: %w", err)
+	}
+
+	if _, err := db.Exec(createProblemTagsTable); err != nil {
+		return fmt.Errorf("create problem_tags table: %w", err)
+	}
+
+	if _, err := db.Exec(createProblemCompaniesTable); err != nil {
+		return fmt.Errorf("create problem_companies table: %w", err)
+	}
+
+	if _, err := db.Exec(createStatementsTable); err != nil {
+		return fmt.Errorf("create statements table: %w", err)
+	}
+
+	if _, err := db.Exec(createHintsTable); err != nil {
+		return fmt.Errorf("create hints table: %w", err)
+	}
+
+	if _, err := db.Exec(createRelatedProblemsTable); err != nil {
+		return fmt.Errorf("create related_problems table: %w", err)
+	}
+
+	if _, err := db.Exec(createDrillReviewsTable); err != nil {
+		return fmt.Errorf("create drill_reviews table: %w", err)
+	}
+
+	if _, err := db.Exec(createComplexitiesTable); err != nil {
+		return fmt.Errorf("create complexities table: %w", err)
+	}
+
+	if _, err := db.Exec(createQuizReviewsTable); err != nil {
+		return fmt.Errorf("create quiz_reviews table: %w", err)
+	}
+
+	return migrateTables(db)
+}
+
+// migrateTables adds columns introduced after a database was first created.
+func migrateTables(db *sql.DB) error {
+	migrations := []struct {
+		table, column, definition string
+	}{
+		{"problems", "state", "TEXT NOT NULL DEFAULT 'active'"},
+		{"problems", "buried_until", "DATETIME"},
+		{"problems", "source", "TEXT NOT NULL DEFAULT 'leetcode'"},
+		{"problems", "slug", "TEXT"},
+		{"problems", "url", "TEXT"},
+		{"completions", "hints_used", "INTEGER NOT NULL DEFAULT 0"},
+	}
+
+	for _, m := range migrations {
+		if err := addColumnIfMissing(db, m.table, m.column, m.definition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func addColumnIfMissing(db *sql.DB, table, column, definition string) error {
+	var exists int
+	err := db.QueryRow("SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", table, column).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("inspect %s.%s: %w", table, column, err)
+	}
+	if exists > 0 {
+		return nil
+	}
+
+	if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+		return fmt.Errorf("add column %s.%s: %w", table, column, err)
+	}
 	return nil
 }
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ==================== Pattern Drill ====================
+
+// Drill modes: guess the problem's topic grouping, or one of its tags.
+const (
+	drillByTopic = "topic"
+	drillByTag   = "tag"
+)
+
+const drillChoices = 4
+
+// drillBoxDays is the Leitner schedule for drill cards: a correct answer
+// moves a card up one box, a wrong answer sends it back to the first.
+var drillBoxDays = []int{1, 2, 4, 8, 16, 32}
+
+type DrillCard struct {
+	Problem
+	Box int // 0 for never drilled
+}
+
+type DrillAccuracy struct {
+	Name    string
+	Correct int
+	Total   int
+}
+
+func (a DrillAccuracy) Percent() float64 {
+	if a.Total == 0 {
+		return 0
+	}
+	return float64(a.Correct) / float64(a.Total) * 100
+}
+
+// nextDrillBox returns the box and interval after answering a card.
+func nextDrillBox(box int, correct bool) (int, int) {
+	if !correct {
+		return 1, drillBoxDays[0]
+	}
+	box = min(box+1, len(drillBoxDays))
+	return box, drillBoxDays[box-1]
+}
+
+// selectDrillCards returns cards due for drilling (most overdue first),
+// topped up with never-drilled problems in random order.
+func selectDrillCards(db *sql.DB, mode string, filter ProblemFilter, count int) ([]DrillCard, error) {
+	clause, args := filter.where()
+	if mode == drillByTag {
+		clause += " AND EXISTS (SELECT 1 FROM problem_tags WHERE problem_id = p.id)"
+	} else {
+		clause += " AND COALESCE(p.grouping, '') != ''"
+	}
+
+	rows, err := db.Query(`
+		SELECT `+problemColumns+`, COALESCE(d.box, 0)
+		FROM problems p
+		LEFT JOIN (
+			SELECT problem_id, MAX(id), box, next_review_date
+			FROM drill_reviews
+			WHERE mode = ?
+			GROUP BY problem_id
+		) d ON p.id = d.problem_id
+		WHERE `+eligibleProblemFilter+clause+`
+			AND (d.next_review_date IS NULL OR date(d.next_review_date) <= date('now'))
+		ORDER BY d.next_review_date IS NULL, d.next_review_date, RANDOM()
+		LIMIT ?
+	`, append(append([]any{mode}, args...), count)...)
+	if err != nil {
+		return nil, fmt.Errorf("query drill cards: %w", err)
+	}
+	defer rows.Close()
+
+	var cards []DrillCard
+	for rows.Next() {
+		var c DrillCard
+		var err error
+		c.Problem, err = scanProblem(rows, &c.Box)
+		if err != nil {
+			return nil, fmt.Errorf("scan problem: %w", err)
+		}
+		cards = append(cards, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate rows: %w", err)
+	}
+	return cards, nil
+}
+
+// getDrillAnswers lists every possible answer for a mode: all topic
+// groupings, or all tags.
+func getDrillAnswers(db *sql.DB, mode string) ([]string, error) {
+	query := "SELECT DISTINCT grouping FROM problems WHERE COALESCE(grouping, '') != '' ORDER BY grouping"
+	if mode == drillByTag {
+		query = "SELECT name FROM tags ORDER BY name"
+	}
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("query drill answers: %w", err)
+	}
+	defer rows.Close()
+
+	var answers []string
+	for rows.Next() {
+		var a string
+		if err := rows.Scan(&a); err != nil {
+			return nil, fmt.Errorf("scan answer: %w", err)
+		}
+		answers = append(answers, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate rows: %w", err)
+	}
+	return answers, nil
+}
+
+// drillQuestion picks the correct answer for a card and shuffles it in with
+// distractors. Any of the problem's tags counts as correct, so distractors
+// never include them.
+func drillQuestion(card DrillCard, mode string, tags, answers []string) (choices []string, correct string) {
+	correctSet := []string{card.Grouping}
+	if mode == drillByTag {
+		correctSet = tags
+	}
+	correct = correctSet[rand.IntN(len(correctSet))]
+
+	var distractors []string
+	for _, a := range answers {
+		if !slices.Contains(correctSet, a) {
+			distractors = append(distractors, a)
+		}
+	}
+	rand.Shuffle(len(distractors), func(i, j int) { distractors[i], distractors[j] = distractors[j], distractors[i] })
+
+	choices = append(distractors[:min(drillChoices-1, len(distractors))], correct)
+	rand.Shuffle(len(choices), func(i, j int) { choices[i], choices[j] = choices[j], choices[i] })
+	return choices, correct
+}
+
+func recordDrillAnswer(db *sql.DB, card DrillCard, mode string, correct bool) error {
+	box, interval := nextDrillBox(card.Box, correct)
+	return session.record(func() error {
+		_, err := db.Exec(`
+			INSERT INTO drill_reviews (problem_id, mode, correct, box, next_review_date)
+			VALUES (?, ?, ?, ?, datetime('now', '+' || ? || ' days'))
+		`, card.ID, mode, correct, box, interval)
+		if err != nil {
+			return fmt.Errorf("record drill answer: %w", err)
+		}
+		session.drilled++
+		return nil
+	})
+}
+
+// getDrillAccuracy returns accuracy per topic grouping (worst first) and overall.
+func getDrillAccuracy(db *sql.DB) ([]DrillAccuracy, DrillAccuracy, error) {
+	rows, err := db.Query(`
+		SELECT COALESCE(p.grouping, ''), SUM(d.correct), COUNT(*)
+		FROM drill_reviews d
+		INNER JOIN problems p ON p.id = d.problem_id
+		GROUP BY p.grouping
+		ORDER BY CAST(SUM(d.correct) AS REAL) / COUNT(*), p.grouping
+	`)
+	if err != nil {
+		return nil, DrillAccuracy{}, fmt.Errorf("query drill accuracy: %w", err)
+	}
+	defer rows.Close()
+
+	overall := DrillAccuracy{Name: "Overall"}
+	var byTopic []DrillAccuracy
+	for rows.Next() {
+		var a DrillAccuracy
+		if err := rows.Scan(&a.Name, &a.Correct, &a.Total); err != nil {
+			return nil, DrillAccuracy{}, fmt.Errorf("scan accuracy: %w", err)
+		}
+		overall.Correct += a.Correct
+		overall.Total += a.Total
+		byTopic = append(byTopic, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, DrillAccuracy{}, fmt.Errorf("iterate rows: %w", err)
+	}
+	return byTopic, overall, nil
+}
+
+func printDrillStats(db *sql.DB) error {
+	byTopic, overall, err := getDrillAccuracy(db)
+	if err != nil {
+		return err
+	}
+	if overall.Total == 0 {
+		fmt.Println("\nNo drills yet. Start one with 'drill'.")
+		return nil
+	}
+
+	fmt.Println("\n" + theme.icon("🎯") + "Pattern Recognition:")
+	fmt.Println("========================")
+	table := Table{
+		Indent:  "  ",
+		Columns: []Column{{Flex: true, Min: 10}, {Right: true}, {Right: true}},
+	}
+	for _, a := range append([]DrillAccuracy{overall}, byTopic...) {
+		table.AddRow(a.Name, fmt.Sprintf("%d/%d", a.Correct, a.Total), fmt.Sprintf("(%.0f%%)", a.Percent()))
+	}
+	// Overall goes above the rest, set apart, but lined up with them
+	lines := table.Lines()
+	fmt.Println(lines[0])
+	fmt.Println()
+	for _, line := range lines[1:] {
+		fmt.Println(line)
+	}
+	fmt.Println()
+	return nil
+}
+
+type drillOptions struct {
+	Filter ProblemFilter
+	Count  int
+	Mode   string
+}
+
+func drillFlags(o *drillOptions) *flag.FlagSet {
+	fs := newFlagSet("drill")
+	filterFlags(fs, &o.Filter)
+	fs.IntVar(&o.Count, "count", 10, "Number of cards")
+	fs.IntVar(&o.Count, "c", 10, "Short for count")
+	fs.StringVar(&o.Mode, "by", drillByTopic, "What to guess: topic or tag")
+	return fs
+}
+
+func drillCommandWithDB(db *sql.DB, args []string) error {
+	if len(args) > 0 && args[0] == "stats" {
+		return printDrillStats(db)
+	}
+
+	var opts drillOptions
+	if err := drillFlags(&opts).Parse(args); err != nil {
+		return err
+	}
+	mode := opts.Mode
+	if mode != drillByTopic && mode != drillByTag {
+		return fmt.Errorf("--by must be %s or %s", drillByTopic, drillByTag)
+	}
+	if !interactive {
+		return fmt.Errorf("drill asks for answers, so it needs an interactive terminal")
+	}
+	if val, ok := shortToLong[opts.Filter.Difficulty]; ok {
+		opts.Filter.Difficulty = val
+	}
+
+	cards, err := selectDrillCards(db, mode, opts.Filter, opts.Count)
+	if err != nil {
+		return err
+	}
+	answers, err := getDrillAnswers(db, mode)
+	if err != nil {
+		return err
+	}
+	if len(answers) < 2 {
+		return fmt.Errorf("drilling by %s needs at least two different answers; add some with 'tag add'", mode)
+	}
+	if len(cards) == 0 {
+		fmt.Println("\nNothing to drill right now. Come back tomorrow!")
+		return nil
+	}
+
+	fmt.Printf("\n%sPattern Drill: name the %s for each problem\n", theme.icon("🎯"), mode)
+	fmt.Println("========================")
+
+	reader := stdin
+	score, asked := 0, 0
+	for i, card := range cards {
+		var tags []string
+		if mode == drillByTag {
+			if tags, err = getProblemTags(db, card.ID); err != nil {
+				return err
+			}
+		}
+		choices, correct := drillQuestion(card, mode, tags, answers)
+
+		fmt.Printf("\n%d/%d. [%s] %s (%s)\n", i+1, len(cards), card.Ref(), card.Title, card.Difficulty)
+		statement, found, err := getStatement(db, card.ID)
+		if err != nil {
+			return err
+		}
+		if found {
+			for _, line := range wrapText(statement.Body, "  ", statementWidth) {
+				fmt.Println(line)
+			}
+		}
+		fmt.Println()
+		for j, c := range choices {
+			fmt.Printf("  %d) %s\n", j+1, c)
+		}
+
+		var pick int
+		for {
+			fmt.Printf("Answer (1-%d, q to quit): ", len(choices))
+			input, err := reader.ReadString('\n')
+			if err != nil {
+				return err
+			}
+			input = strings.TrimSpace(strings.ToLower(input))
+			if input == "q" || input == "quit" {
+				pick = -1
+				break
+			}
+			if pick, err = strconv.Atoi(input); err == nil && pick >= 1 && pick <= len(choices) {
+				break
+			}
+			fmt.Printf("Invalid answer: %s\n", input)
+		}
+		if pick < 0 {
+			break
+		}
+
+		right := choices[pick-1] == correct
+		if err := recordDrillAnswer(db, card, mode, right); err != nil {
+			return err
+		}
+		asked++
+		if right {
+			score++
+			fmt.Println(success("Correct!"))
+		} else {
+			fmt.Println(failure("It's %s", correct))
+		}
+	}
+
+	if asked > 0 {
+		fmt.Printf("\nScore: %d/%d (%.0f%%)\n", score, asked, float64(score)/float64(asked)*100)
+		fmt.Println("Run 'drill stats' to see accuracy by topic.")
+	}
+	fmt.Println()
+	return nil
+}
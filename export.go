@@ -0,0 +1,177 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ==================== Export ====================
+
+type ExportRow struct {
+	Problem
+	State          string  `json:"state"`
+	Completions    int     `json:"completions"`
+	LastCompleted  string  `json:"last_completed,omitempty"`
+	NextReview     string  `json:"next_review,omitempty"`
+	EasinessFactor float64 `json:"easiness_factor,omitempty"`
+	Repetitions    int     `json:"repetitions,omitempty"`
+}
+
+func getExportRows(db *sql.DB, filter ProblemFilter) ([]ExportRow, error) {
+	clause, args := filter.where()
+	rows, err := db.Query(`
+		SELECT `+problemColumns+`,
+			p.state,
+			COALESCE(n.completions, 0),
+			COALESCE(c.completed_at, ''),
+			COALESCE(c.next_review_date, ''),
+			COALESCE(c.easiness_factor, 0),
+			COALESCE(c.repetitions, 0)
+		FROM problems p
+		LEFT JOIN (
+			SELECT problem_id, MAX(completed_at) as completed_at, next_review_date,
+				easiness_factor, repetitions
+			FROM completions
+			GROUP BY problem_id
+		) c ON p.id = c.problem_id
+		LEFT JOIN (
+			SELECT problem_id, COUNT(*) as completions
+			FROM completions
+			GROUP BY problem_id
+		) n ON p.id = n.problem_id
+		WHERE 1 = 1`+clause+`
+		ORDER BY p.id
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query export: %w", err)
+	}
+	defer rows.Close()
+
+	var export []ExportRow
+	for rows.Next() {
+		var r ExportRow
+		var err error
+		r.Problem, err = scanProblem(rows,
+			&r.State, &r.Completions, &r.LastCompleted, &r.NextReview, &r.EasinessFactor, &r.Repetitions)
+		if err != nil {
+			return nil, fmt.Errorf("scan export row: %w", err)
+		}
+		export = append(export, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate rows: %w", err)
+	}
+
+	tags, err := getAllProblemTags(db)
+	if err != nil {
+		return nil, err
+	}
+	companies, err := getAllProblemCompanies(db)
+	if err != nil {
+		return nil, err
+	}
+	for i := range export {
+		export[i].Tags = tags[export[i].ID]
+		export[i].Companies = companies[export[i].ID]
+	}
+
+	return export, nil
+}
+
+func writeExportCSV(w io.Writer, rows []ExportRow) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"title", "difficulty", "grouping", "leetcode_number", "tags", "companies", "notes", "state",
+		"completions", "last_completed", "next_review", "easiness_factor", "repetitions"})
+	for _, r := range rows {
+		var companies []string
+		for _, c := range r.Companies {
+			companies = append(companies, c.Name)
+		}
+		cw.Write([]string{
+			r.Title, r.Difficulty, r.Grouping, strconv.Itoa(r.LeetcodeNumber),
+			strings.Join(r.Tags, ";"), strings.Join(companies, ";"), r.Notes, r.State,
+			strconv.Itoa(r.Completions), r.LastCompleted, r.NextReview,
+			strconv.FormatFloat(r.EasinessFactor, 'f', 2, 64), strconv.Itoa(r.Repetitions),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+type exportOptions struct {
+	Format string
+	Out    string
+	Filter ProblemFilter
+}
+
+func exportFlags(o *exportOptions) *flag.FlagSet {
+	fs := newFlagSet("export")
+	fs.StringVar(&o.Format, "format", "json", "Output format (json, csv)")
+	fs.StringVar(&o.Out, "out", "", "File to write (defaults to the terminal)")
+	fs.StringVar(&o.Out, "o", "", "Short for out")
+	filterFlags(fs, &o.Filter)
+	return fs
+}
+
+func exportCommandWithDB(db *sql.DB, args []string) error {
+	var opts exportOptions
+	err := exportFlags(&opts).Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if val, ok := shortToLong[opts.Filter.Difficulty]; ok {
+		opts.Filter.Difficulty = val
+	}
+	// Check the format before --out creates, and so empties, the file
+	opts.Format = strings.ToLower(opts.Format)
+	if opts.Format != "json" && opts.Format != "csv" {
+		return fmt.Errorf("unknown format %q (use json or csv)", opts.Format)
+	}
+
+	rows, err := getExportRows(db, opts.Filter)
+	if err != nil {
+		return err
+	}
+
+	if opts.Out == "" {
+		return writeExport(os.Stdout, opts.Format, rows)
+	}
+	f, err := os.Create(opts.Out)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", opts.Out, err)
+	}
+	if err := writeExport(f, opts.Format, rows); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("write export: %w", err)
+	}
+
+	fmt.Println(success("Exported %d problems to %s", len(rows), opts.Out))
+	return nil
+}
+
+// writeExport writes rows to w as json or csv.
+func writeExport(w io.Writer, format string, rows []ExportRow) error {
+	var err error
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(rows)
+	} else {
+		err = writeExportCSV(w, rows)
+	}
+	if err != nil {
+		return fmt.Errorf("write export: %w", err)
+	}
+	return nil
+}
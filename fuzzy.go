@@ -0,0 +1,125 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ==================== Fuzzy Matching ====================
+
+// Match tiers, best first. fuzzyScore returns tier*100 plus a bonus below 100
+// that ranks matches within the same tier.
+const (
+	tierExact       = 6
+	tierPrefix      = 5
+	tierWord        = 4
+	tierSubstring   = 3
+	tierAllWords    = 2
+	tierTypo        = 1
+	tierSubsequence = 0
+)
+
+// fuzzyScore scores how well query matches target. Negative means no match.
+func fuzzyScore(query, target string) int {
+	q := normalizeForMatch(query)
+	t := normalizeForMatch(target)
+	if q == "" || t == "" {
+		return -1
+	}
+
+	// Shorter targets rank higher within a tier
+	bonus := max(99-len(t), 0)
+
+	switch {
+	case q == t:
+		return tierExact*100 + 99
+	case strings.HasPrefix(t, q):
+		return tierPrefix*100 + bonus
+	case strings.Contains(" "+t, " "+q):
+		return tierWord*100 + bonus
+	case strings.Contains(t, q):
+		return tierSubstring*100 + bonus
+	case containsAllWords(t, q):
+		return tierAllWords*100 + bonus
+	}
+
+	// Tolerate roughly one typo per four characters
+	if d := editDistance(q, t); d <= max(len(q)/4, 1) {
+		return tierTypo*100 + max(99-d*10, 0)
+	}
+
+	if gaps, ok := subsequenceGaps(strings.ReplaceAll(q, " ", ""), t); ok {
+		return tierSubsequence*100 + max(99-gaps, 0)
+	}
+
+	return -1
+}
+
+// normalizeForMatch lowercases s and collapses punctuation and whitespace
+// into single spaces so "Two-Sum II" matches "two sum ii".
+func normalizeForMatch(s string) string {
+	var b strings.Builder
+	space := true
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			space = false
+		} else if !space {
+			b.WriteByte(' ')
+			space = true
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func containsAllWords(target, query string) bool {
+	for _, w := range strings.Fields(query) {
+		if !strings.Contains(target, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// subsequenceGaps reports whether q is a subsequence of t and how many
+// characters of t were skipped between the first and last matched character.
+func subsequenceGaps(q, t string) (int, bool) {
+	qi, gaps, started := 0, 0, false
+	for i := 0; i < len(t) && qi < len(q); i++ {
+		if t[i] == q[qi] {
+			qi++
+			started = true
+		} else if started {
+			gaps++
+		}
+	}
+	return gaps, qi == len(q)
+}
+
+// editDistance is the optimal string alignment distance: insertions,
+// deletions, substitutions and adjacent transpositions each cost one.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
@@ -2,4 +2,9 @@ module github.com/JMoooore/GoStudyNeetCode
 
 go 1.25.1
 
-require github.com/mattn/go-sqlite3 v1.14.32 // indirect
+require (
+	github.com/mattn/go-sqlite3 v1.14.32
+	golang.org/x/term v0.45.0
+)
+
+require golang.org/x/sys v0.47.0 // indirect
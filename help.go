@@ -0,0 +1,181 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// ==================== Help ====================
+
+// newFlagSet returns a flag set that returns errors without printing Go's
+// default usage; 'help <command>' documents flags instead.
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+// isHelpArg reports whether args ask for help, so every command treats -h
+// the same whether or not it has flags. Only flag positions count: a -h
+// after "--" or given as the value of one of cmd's flags is left to the
+// command.
+func isHelpArg(cmd CliCommand, args []string) bool {
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		switch {
+		case a == "--":
+			return false
+		case a == "-h" || a == "-help" || a == "--help":
+			return true
+		case strings.HasPrefix(a, "-") && !strings.Contains(a, "=") && takesValue(cmd, strings.TrimLeft(a, "-")):
+			i++ // Skip the flag's value
+		}
+	}
+	return false
+}
+
+// takesValue reports whether name is one of cmd's flags that isn't boolean,
+// so the next argument is its value.
+func takesValue(cmd CliCommand, name string) bool {
+	if cmd.Flags == nil {
+		return false
+	}
+	for _, fs := range cmd.Flags() {
+		if f := fs.Lookup(name); f != nil {
+			b, ok := f.Value.(interface{ IsBoolFlag() bool })
+			return !ok || !b.IsBoolFlag()
+		}
+	}
+	return false
+}
+
+// flagNames returns every flag a command accepts, spelled the way they're
+// usually typed: -x for short flags, --name for long ones.
+func flagNames(cmd CliCommand) []string {
+	if cmd.Flags == nil {
+		return nil
+	}
+	var names []string
+	for _, fs := range cmd.Flags() {
+		fs.VisitAll(func(f *flag.Flag) {
+			names = append(names, flagSpelling(f.Name))
+		})
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+func flagSpelling(name string) string {
+	if len(name) == 1 {
+		return "-" + name
+	}
+	return "--" + name
+}
+
+// flagHelpLines renders a flag set as aligned lines, pairing each short flag
+// ("Short for count") with its long form.
+func flagHelpLines(fs *flag.FlagSet) []string {
+	shorts := map[string]string{}
+	fs.VisitAll(func(f *flag.Flag) {
+		if long, ok := strings.CutPrefix(f.Usage, "Short for "); ok && fs.Lookup(long) != nil {
+			shorts[long] = f.Name
+		}
+	})
+
+	type row struct{ names, usage string }
+	var rows []row
+	width := 0
+	fs.VisitAll(func(f *flag.Flag) {
+		if long, ok := strings.CutPrefix(f.Usage, "Short for "); ok && fs.Lookup(long) != nil {
+			return // Listed with its long form
+		}
+
+		names := "    " + flagSpelling(f.Name)
+		if short, ok := shorts[f.Name]; ok {
+			names = "-" + short + ", " + flagSpelling(f.Name)
+		}
+		typeName, usage := flag.UnquoteUsage(f)
+		if typeName != "" {
+			names += " " + typeName
+		}
+		if f.DefValue != "" && f.DefValue != "0" && f.DefValue != "false" && f.DefValue != "[]" {
+			usage += fmt.Sprintf(" (default %s)", f.DefValue)
+		}
+		rows = append(rows, row{names, usage})
+		width = max(width, len(names))
+	})
+
+	var lines []string
+	for _, r := range rows {
+		lines = append(lines, fmt.Sprintf("  %-*s  %s", width, r.names, r.usage))
+	}
+	return lines
+}
+
+func printCommandHelp(cmd CliCommand) {
+	usage := cmd.Usage
+	if usage == "" {
+		usage = cmd.Name
+	}
+
+	fmt.Printf("\n%s - %s\n\n", cmd.Name, cmd.Description)
+	fmt.Println("Usage:")
+	for _, line := range strings.Split(usage, "\n") {
+		fmt.Println("  " + line)
+	}
+
+	if cmd.Flags != nil {
+		sets := cmd.Flags()
+		for _, fs := range sets {
+			lines := flagHelpLines(fs)
+			if len(lines) == 0 {
+				continue
+			}
+			if len(sets) > 1 {
+				fmt.Printf("\nFlags for %s:\n", fs.Name())
+			} else {
+				fmt.Println("\nFlags:")
+			}
+			for _, line := range lines {
+				fmt.Println(line)
+			}
+		}
+	}
+
+	if len(cmd.Examples) > 0 {
+		fmt.Println("\nExamples:")
+		for _, ex := range cmd.Examples {
+			fmt.Println("  " + ex)
+		}
+	}
+	fmt.Println()
+}
+
+func helpCommand(args []string) error {
+	commands := getCommands(nil)
+	if len(args) > 0 {
+		cmd, ok := commands[args[0]]
+		if !ok {
+			return fmt.Errorf("no command named '%s'; type 'help' for the list", args[0])
+		}
+		printCommandHelp(cmd)
+		return nil
+	}
+
+	fmt.Println()
+	fmt.Println("Available Commands:")
+	fmt.Println("==================")
+
+	for _, name := range slices.Sorted(maps.Keys(commands)) {
+		fmt.Printf("  %-10s - %s\n", name, commands[name].Description)
+	}
+	fmt.Println()
+	printShortcuts()
+	fmt.Println("Type 'help <command>' (or '<command> -h') for its flags and examples.")
+	fmt.Println()
+	return nil
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// ==================== Hints ====================
+
+// hintLevels names the rungs of the hint ladder, from the gentlest nudge to
+// a sketch of the solution. Problem.Hints and the hints table use the same order.
+var hintLevels = []string{"Pattern", "Data structure", "Approach"}
+
+type Hint struct {
+	Level int // 1-based index into hintLevels
+	Text  string
+}
+
+func (h Hint) Label() string {
+	return hintLevels[h.Level-1]
+}
+
+func setHint(db execer, problemID, level int, text string) error {
+	if level < 1 || level > len(hintLevels) {
+		return fmt.Errorf("hint level must be between 1 and %d", len(hintLevels))
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		_, err := db.Exec("DELETE FROM hints WHERE problem_id = ? AND level = ?", problemID, level)
+		if err != nil {
+			return fmt.Errorf("delete hint: %w", err)
+		}
+		return nil
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO hints (problem_id, level, text)
+		VALUES (?, ?, ?)
+		ON CONFLICT(problem_id, level) DO UPDATE SET text = excluded.text
+	`, problemID, level, text)
+	if err != nil {
+		return fmt.Errorf("save hint: %w", err)
+	}
+	return nil
+}
+
+// getHints returns a problem's hints in ladder order. Levels without a hint
+// are skipped.
+func getHints(db *sql.DB, problemID int) ([]Hint, error) {
+	rows, err := db.Query("SELECT level, text FROM hints WHERE problem_id = ? ORDER BY level", problemID)
+	if err != nil {
+		return nil, fmt.Errorf("query hints: %w", err)
+	}
+	defer rows.Close()
+
+	var hints []Hint
+	for rows.Next() {
+		var h Hint
+		if err := rows.Scan(&h.Level, &h.Text); err != nil {
+			return nil, fmt.Errorf("scan hint: %w", err)
+		}
+		hints = append(hints, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate rows: %w", err)
+	}
+	return hints, nil
+}
+
+func clearHints(db *sql.DB, problemID int) (int64, error) {
+	res, err := db.Exec("DELETE FROM hints WHERE problem_id = ?", problemID)
+	if err != nil {
+		return 0, fmt.Errorf("delete hints: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// revealHint prints the next unrevealed hint for p and returns the new number
+// of hints used. It returns revealed unchanged when the ladder is exhausted.
+func revealHint(db *sql.DB, p Problem, revealed int) (int, error) {
+	hints, err := getHints(db, p.ID)
+	if err != nil {
+		return revealed, err
+	}
+	if len(hints) == 0 {
+		fmt.Printf("'%s' has no hints; add some with 'hint set'.\n", p.Title)
+		return revealed, nil
+	}
+	if revealed >= len(hints) {
+		fmt.Printf("No more hints for '%s'.\n", p.Title)
+		return revealed, nil
+	}
+
+	h := hints[revealed]
+	fmt.Printf("%sHint %d/%d for '%s' (%s): %s\n", theme.icon("💡"), revealed+1, len(hints), p.Title, h.Label(), h.Text)
+	return revealed + 1, nil
+}
+
+// hintSetFlags binds one flag per hint level to texts, which must have
+// len(hintLevels) entries.
+func hintSetFlags(texts []string) *flag.FlagSet {
+	fs := newFlagSet("hint set")
+	fs.StringVar(&texts[0], "pattern", "", "Level 1: the pattern, e.g. \"sliding window\"")
+	fs.StringVar(&texts[1], "structure", "", "Level 2: the key data structure")
+	fs.StringVar(&texts[2], "approach", "", "Level 3: a sketch of the approach")
+	return fs
+}
+
+func hintCommandWithDB(db *sql.DB, args []string) error {
+	usage := fmt.Errorf("usage: hint set <problem> [--pattern ..] [--structure ..] [--approach ..], hint show <problem>, or hint clear <problem>")
+	if len(args) == 0 {
+		return usage
+	}
+
+	switch args[0] {
+	case "set":
+		texts := make([]string, len(hintLevels))
+		fs := hintSetFlags(texts)
+
+		positional, err := parseInterspersed(fs, args[1:])
+		if err != nil {
+			return err
+		}
+		if len(positional) == 0 {
+			return usage
+		}
+		if fs.NFlag() == 0 {
+			return fmt.Errorf("nothing to set; pass --pattern, --structure or --approach")
+		}
+
+		problem, err := resolveProblem(db, strings.Join(positional, " "))
+		if err != nil {
+			return err
+		}
+
+		// Only touch the levels that were passed, so hints can be filled in one at a time
+		set := map[string]bool{}
+		fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+		for i, name := range []string{"pattern", "structure", "approach"} {
+			if !set[name] {
+				continue
+			}
+			if err := setHint(db, problem.ID, i+1, texts[i]); err != nil {
+				return err
+			}
+		}
+		fmt.Println(success("Updated hints for '%s'", problem.Title))
+		return nil
+
+	case "show":
+		if len(args) < 2 {
+			return usage
+		}
+		problem, err := resolveProblem(db, strings.Join(args[1:], " "))
+		if err != nil {
+			return err
+		}
+		hints, err := getHints(db, problem.ID)
+		if err != nil {
+			return err
+		}
+		if len(hints) == 0 {
+			fmt.Printf("'%s' has no hints.\n", problem.Title)
+			return nil
+		}
+		fmt.Printf("\n%s%s:\n", theme.icon("💡"), problem.Title)
+		for _, h := range hints {
+			fmt.Printf("  %d. %-15s %s\n", h.Level, h.Label()+":", h.Text)
+		}
+		fmt.Println()
+		return nil
+
+	case "clear":
+		if len(args) < 2 {
+			return usage
+		}
+		problem, err := resolveProblem(db, strings.Join(args[1:], " "))
+		if err != nil {
+			return err
+		}
+		removed, err := clearHints(db, problem.ID)
+		if err != nil {
+			return err
+		}
+		if removed == 0 {
+			return fmt.Errorf("'%s' has no hints", problem.Title)
+		}
+		fmt.Println(success("Cleared %d hints from '%s'", removed, problem.Title))
+		return nil
+	}
+
+	return usage
+}
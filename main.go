@@ -1,46 +1,191 @@
 package main
 
 import (
-	"bufio"
 	"database/sql"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
 	"strings"
+	"unicode"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
-func startRepl(db *sql.DB) {
-	reader := bufio.NewScanner(os.Stdin)
-
+// startRepl reads and runs commands until exit or the end of input. When
+// input isn't interactive it stops at the first failing command and returns
+// its error.
+func startRepl(db *sql.DB, editor *lineEditor) error {
 	for {
-		fmt.Print("GoStudy > ")
-		reader.Scan()
-
-		input := reader.Text()
-		parts := strings.Fields(input)
+		input, err := editor.ReadLine("GoStudy > ")
+		if errors.Is(err, errInterrupted) {
+			continue
+		}
+		if err != nil {
+			// End of input (Ctrl-D or the end of a pipe)
+			if interactive {
+				fmt.Println()
+			}
+			return nil
+		}
 
-		if len(parts) == 0 {
+		err = runCommand(db, input)
+		if errors.Is(err, errExit) {
+			return nil
+		}
+		if errors.Is(err, errInterrupted) {
+			fmt.Println() // After the ^C the terminal echoed
 			continue
 		}
+		if err != nil {
+			if !interactive {
+				return err
+			}
+			fmt.Println(err)
+		}
+	}
+}
+
+// runScript runs the ';'-separated commands given to --exec, stopping at the
+// first one that fails.
+func runScript(db *sql.DB, script string) error {
+	commands, err := splitCommands(script)
+	if err != nil {
+		return err
+	}
+	for _, line := range commands {
+		err := runCommand(db, line)
+		if errors.Is(err, errExit) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runCommand parses and runs one command line. Blank lines do nothing.
+func runCommand(db *sql.DB, input string) error {
+	return runExpanded(db, input, 0)
+}
+
+// runExpanded is runCommand for a line that may itself come from expanding
+// depth aliases or macros.
+func runExpanded(db *sql.DB, input string, depth int) error {
+	parts, err := splitArgs(input)
+	if err != nil {
+		return err
+	}
+	if len(parts) == 0 {
+		return nil
+	}
 
-		commandName := parts[0]
-		args := parts[1:]
+	commandName := parts[0]
+	args := parts[1:]
 
-		command, exists := getCommands(db)[commandName]
-		if exists {
-			err := command.Callback(args)
-			if err != nil {
-				fmt.Println(err)
+	// Aliases and macros are resolved first; they can't reuse built-in names
+	if ok, err := expandShortcut(db, commandName, input, depth); ok {
+		return err
+	}
+
+	command, exists := getCommands(db)[commandName]
+	if !exists {
+		return fmt.Errorf("Unknown command: %s. Type 'help' for available commands.", commandName)
+	}
+	if isHelpArg(command, args) {
+		printCommandHelp(command)
+		return nil
+	}
+	return command.Callback(args)
+}
+
+// splitCommands splits a script on semicolons outside quotes.
+func splitCommands(script string) ([]string, error) {
+	var commands []string
+	var current strings.Builder
+	var quote rune
+	escaped := false
+
+	for _, r := range script {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
 			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == ';':
+			commands = append(commands, current.String())
+			current.Reset()
 			continue
 		}
+		current.WriteRune(r)
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	return append(commands, current.String()), nil
+}
+
+// splitArgs splits a command line on whitespace, keeping single- or
+// double-quoted sections together so titles with spaces can be passed as one
+// argument. A backslash escapes the next character outside single quotes.
+func splitArgs(input string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+
+	for _, r := range input {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inArg = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
 
-		fmt.Printf("Unknown command: %s. Type 'help' for available commands.\n", commandName)
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inArg {
+		args = append(args, current.String())
 	}
+	return args, nil
 }
 
-func main() {
+func printBanner() {
+	if theme.ASCII {
+		fmt.Print("\nGO STUDY - NeetCode Practice CLI\n\n")
+		return
+	}
 	fmt.Println(`
   ██████╗  ██████╗     ███████╗████████╗██╗   ██╗██████╗ ██╗   ██╗
  ██╔════╝ ██╔═══██╗    ██╔════╝╚══██╔══╝██║   ██║██╔══██╗╚██╗ ██╔╝
@@ -52,14 +197,59 @@ func main() {
                🚀 NeetCode Practice CLI 🚀
 	`)
 	fmt.Println()
+}
+
+func main() {
+	var script string
+	flag.StringVar(&script, "exec", "", "Run these ';'-separated commands and exit, e.g. \"study -c 3; stat\"")
+	flag.StringVar(&script, "e", "", "Short for exec")
+	flag.StringVar(&themeFlag, "theme", "", "Output theme: color, no-color, high-contrast or ascii; forces color on even when piped")
+	var profileName string
+	flag.StringVar(&profileName, "profile", "", "Use the settings of this profile in config.json")
+	flag.Parse()
+	if script != "" {
+		interactive = false
+	}
+
+	if err := loadConfig(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	activeProfile = config.Profile
+	if profileName != "" {
+		activeProfile = profileName
+	}
+	if err := applySettings(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if interactive {
+		printBanner()
+	}
 
 	db, err := initDb()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to initialize database: %v\n", err)
 		os.Exit(1)
 	}
-	defer db.Close()
 
-	fmt.Println("Type 'help' to see available commands")
-	startRepl(db)
+	editor := newLineEditor(db)
+	handleSignals(db)
+
+	if script != "" {
+		err = runScript(db, script)
+	} else {
+		if interactive {
+			fmt.Println("Type 'help' to see available commands")
+		}
+		err = startRepl(db, editor)
+	}
+	shutdown(db)
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// ==================== Deadline Planning ====================
+
+const (
+	targetDateKey      = "target_date"
+	targetDateLayout   = "2006-01-02"
+	deadlineBufferDays = 2  // Final reviews should land at least this many days before the target
+	historyWindowDays  = 14 // Window used for throughput and rating mix
+	planTrials         = 500
+)
+
+type DeadlinePlan struct {
+	TargetDate      time.Time
+	DaysLeft        int
+	NewProblems     int
+	StartedProblems int
+	ExpectedSolves  float64 // Total solves (new + reviews) needed before the target
+	RequiredPace    int     // Solves per day
+	HistoricalPace  float64 // Solves per day over the history window
+	Mix             RatingMix
+	Warnings        []string
+}
+
+// scheduleState is the SM-2 state of a problem that has been completed at least once.
+type scheduleState struct {
+	EasinessFactor float64
+	Interval       int
+	Repetitions    int
+	DaysUntil      int
+}
+
+func getTargetDate(db *sql.DB) (time.Time, bool, error) {
+	value, ok, err := getSetting(db, targetDateKey)
+	if err != nil || !ok {
+		return time.Time{}, false, err
+	}
+	t, err := time.Parse(targetDateLayout, value)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("parse target date %q: %w", value, err)
+	}
+	return t, true, nil
+}
+
+// daysUntilTarget returns whole days between today (UTC) and the target date.
+func daysUntilTarget(target time.Time) int {
+	return daysBetween(time.Now(), target)
+}
+
+func daysBetween(from, target time.Time) int {
+	day := from.UTC().Truncate(24 * time.Hour)
+	return int(target.Sub(day).Hours() / 24)
+}
+
+// capIntervalToTarget shortens an interval starting at `from` so the next
+// review happens shortly before the target date. Without a target (or once it
+// has passed) the interval is returned unchanged.
+func capIntervalToTarget(db *sql.DB, interval int, from time.Time) (int, error) {
+	target, ok, err := getTargetDate(db)
+	if err != nil || !ok {
+		return interval, err
+	}
+
+	daysLeft := daysBetween(from, target)
+	if daysLeft <= 0 {
+		return interval, nil
+	}
+
+	maxInterval := max(daysLeft-deadlineBufferDays, 1)
+	return min(interval, maxInterval), nil
+}
+
+// capScheduledReviews brings reviews already scheduled past the buffer before
+// target forward to it, as capIntervalToTarget does for new completions, and
+// returns how many moved. The stored intervals are left alone.
+func capScheduledReviews(db *sql.DB, target time.Time) (int, error) {
+	limit := target.AddDate(0, 0, -deadlineBufferDays)
+	if tomorrow := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1); limit.Before(tomorrow) {
+		limit = tomorrow
+	}
+
+	result, err := db.Exec(`
+		UPDATE completions
+		SET next_review_date = ?
+		WHERE date(next_review_date) > date(?)
+			AND completed_at = (
+				SELECT MAX(completed_at) FROM completions latest
+				WHERE latest.problem_id = completions.problem_id
+			)
+	`, limit.Format(sqliteTimeLayout), limit.Format(sqliteTimeLayout))
+	if err != nil {
+		return 0, fmt.Errorf("cap scheduled reviews: %w", err)
+	}
+	moved, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("cap scheduled reviews: %w", err)
+	}
+	return int(moved), nil
+}
+
+func getScheduleStates(db *sql.DB, filter ProblemFilter) ([]scheduleState, error) {
+	clause, args := filter.where()
+	rows, err := db.Query(`
+		SELECT
+			c.easiness_factor,
+			c.interval_days,
+			c.repetitions,
+			CAST((julianday(date(c.next_review_date)) - julianday(date('now'))) AS INTEGER)
+		FROM problems p
+		INNER JOIN (
+			SELECT problem_id, MAX(completed_at) as max_completed, next_review_date,
+				easiness_factor, interval_days, repetitions
+			FROM completions
+			GROUP BY problem_id
+		) c ON p.id = c.problem_id
+		WHERE p.state = 'active'`+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query schedule states: %w", err)
+	}
+	defer rows.Close()
+
+	var states []scheduleState
+	for rows.Next() {
+		var s scheduleState
+		if err := rows.Scan(&s.EasinessFactor, &s.Interval, &s.Repetitions, &s.DaysUntil); err != nil {
+			return nil, fmt.Errorf("scan schedule state: %w", err)
+		}
+		states = append(states, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate rows: %w", err)
+	}
+	return states, nil
+}
+
+func buildDeadlinePlan(db *sql.DB, target time.Time) (*DeadlinePlan, error) {
+	plan := &DeadlinePlan{
+		TargetDate: target,
+		DaysLeft:   daysUntilTarget(target),
+	}
+
+	if err := db.QueryRow(`
+		SELECT COUNT(*) FROM problems p
+		WHERE p.state = 'active'
+		AND NOT EXISTS (SELECT 1 FROM completions c WHERE c.problem_id = p.id)
+	`).Scan(&plan.NewProblems); err != nil {
+		return nil, fmt.Errorf("count new problems: %w", err)
+	}
+
+	states, err := getScheduleStates(db, ProblemFilter{})
+	if err != nil {
+		return nil, err
+	}
+	plan.StartedProblems = len(states)
+
+	if plan.Mix, err = getRatingMix(db, historyWindowDays); err != nil {
+		return nil, err
+	}
+	if plan.HistoricalPace, err = getAverageThroughput(db, historyWindowDays); err != nil {
+		return nil, err
+	}
+
+	if plan.DaysLeft <= 0 {
+		plan.Warnings = append(plan.Warnings, "The target date has already passed.")
+		return plan, nil
+	}
+
+	// Monte Carlo over the historical rating mix. New problems are assumed to
+	// be introduced evenly over the days before the review buffer.
+	rng := rand.New(rand.NewPCG(1, 2))
+	lastDay := max(plan.DaysLeft-deadlineBufferDays, 1)
+	var total int
+	for range planTrials {
+		for range plan.NewProblems {
+			start := rng.IntN(lastDay)
+			total += simulateSolvesUntil(rng, plan.Mix, scheduleState{EasinessFactor: settings.InitialEF, Interval: 1, DaysUntil: start}, lastDay)
+		}
+		for _, s := range states {
+			total += simulateSolvesUntil(rng, plan.Mix, s, lastDay)
+		}
+	}
+	plan.ExpectedSolves = float64(total) / planTrials
+	plan.RequiredPace = int(math.Ceil(plan.ExpectedSolves / float64(plan.DaysLeft)))
+
+	if plan.Mix.Samples == 0 {
+		plan.Warnings = append(plan.Warnings,
+			fmt.Sprintf("No completions in the last %d days; assuming all Easy ratings.", historyWindowDays))
+	}
+	if plan.HistoricalPace > 0 && float64(plan.RequiredPace) > plan.HistoricalPace*1.2 {
+		plan.Warnings = append(plan.Warnings,
+			fmt.Sprintf("Infeasible at your current pace: %d/day needed but you average %.1f/day.",
+				plan.RequiredPace, plan.HistoricalPace))
+	}
+	// Without history, judge against the pace stat assumes for new users
+	if plan.HistoricalPace == 0 && float64(plan.RequiredPace) > float64(settings.ProblemsPerDay)*1.2 {
+		plan.Warnings = append(plan.Warnings,
+			fmt.Sprintf("Likely infeasible: %d/day needed, well above a typical %d/day.",
+				plan.RequiredPace, settings.ProblemsPerDay))
+	}
+	if plan.Mix.Hard >= 0.3 {
+		plan.Warnings = append(plan.Warnings,
+			fmt.Sprintf("%.0f%% of recent solves were Hard; each one resets its review schedule.", plan.Mix.Hard*100))
+	}
+
+	return plan, nil
+}
+
+// simulateSolvesUntil counts how many solves a problem needs, starting from
+// state s, until a review lands within the final buffer before lastDay.
+func simulateSolvesUntil(rng *rand.Rand, mix RatingMix, s scheduleState, lastDay int) int {
+	day := max(s.DaysUntil, 0)
+	ef, interval, reps := s.EasinessFactor, s.Interval, s.Repetitions
+
+	solves := 0
+	for day <= lastDay {
+		solves++
+		interval, ef, reps = calculateSM2(mix.sample(rng), 0, ef, interval, reps)
+		if remaining := lastDay - day; remaining > 0 {
+			interval = min(interval, remaining)
+		}
+		day += interval
+	}
+	if solves == 0 && day < lastDay+deadlineBufferDays {
+		// Already due inside the buffer window - one final review
+		solves = 1
+	}
+	return solves
+}
+
+func (m RatingMix) sample(rng *rand.Rand) int {
+	r := rng.Float64()
+	switch {
+	case r < m.Easy:
+		return 1
+	case r < m.Easy+m.Medium:
+		return 2
+	default:
+		return 3
+	}
+}
+
+type planOptions struct {
+	Date  string
+	Clear bool
+}
+
+func planFlags(o *planOptions) *flag.FlagSet {
+	fs := newFlagSet("plan")
+	fs.StringVar(&o.Date, "date", "", "Target interview date (`YYYY-MM-DD`)")
+	fs.BoolVar(&o.Clear, "clear", false, "Remove the target date")
+	return fs
+}
+
+func planCommandWithDB(db *sql.DB, args []string) error {
+	var opts planOptions
+	err := planFlags(&opts).Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if opts.Clear {
+		if err := deleteSetting(db, targetDateKey); err != nil {
+			return err
+		}
+		fmt.Println(success("Target date cleared"))
+		return nil
+	}
+
+	if opts.Date != "" {
+		target, err := time.Parse(targetDateLayout, opts.Date)
+		if err != nil {
+			return fmt.Errorf("invalid date %q (expected YYYY-MM-DD)", opts.Date)
+		}
+		if daysUntilTarget(target) <= 0 {
+			return fmt.Errorf("target date %s is not in the future", opts.Date)
+		}
+		if err := setSetting(db, targetDateKey, target.Format(targetDateLayout)); err != nil {
+			return err
+		}
+		fmt.Println(success("Target date set to %s", target.Format("Jan 2, 2006")))
+		moved, err := capScheduledReviews(db, target)
+		if err != nil {
+			return err
+		}
+		if moved > 0 {
+			fmt.Printf("Brought forward reviews of %d problems so they come up before the target.\n", moved)
+		}
+	}
+
+	target, ok, err := getTargetDate(db)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		fmt.Println("\nNo target date set. Use 'plan --date YYYY-MM-DD' to plan for an interview.")
+		return nil
+	}
+
+	plan, err := buildDeadlinePlan(db, target)
+	if err != nil {
+		return fmt.Errorf("build plan: %w", err)
+	}
+
+	fmt.Println()
+	fmt.Println(theme.icon("🎯") + "Interview Plan")
+	fmt.Println(theme.rule("═", 59))
+	printDeadlinePlan(plan)
+	return nil
+}
+
+func printDeadlinePlan(plan *DeadlinePlan) {
+	fmt.Printf("  Target date:      %s (%d days left)\n", plan.TargetDate.Format("Jan 2, 2006"), plan.DaysLeft)
+	if plan.DaysLeft > 0 {
+		fmt.Printf("  New problems:     %d\n", plan.NewProblems)
+		fmt.Printf("  Started problems: %d\n", plan.StartedProblems)
+		fmt.Printf("  Expected solves:  %.0f (incl. reviews)\n", plan.ExpectedSolves)
+		fmt.Printf("  Required pace:    %d problems/day\n", plan.RequiredPace)
+		fmt.Printf("  Your recent pace: %.1f problems/day (last %d days)\n", plan.HistoricalPace, historyWindowDays)
+		fmt.Printf("  Rating mix:       %.0f%% Easy / %.0f%% Medium / %.0f%% Hard\n",
+			plan.Mix.Easy*100, plan.Mix.Medium*100, plan.Mix.Hard*100)
+		fmt.Printf("  Reviews are scheduled to finish %d days before the target.\n", deadlineBufferDays)
+	}
+	for _, w := range plan.Warnings {
+		fmt.Println("  " + warning("%s", w))
+	}
+	fmt.Println()
+}
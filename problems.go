@@ -0,0 +1,276 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// ==================== Custom Problems ====================
+
+const sourceLeetCode = "leetcode"
+
+var validSources = []string{sourceLeetCode, "hackerrank", "codeforces", "internal", "other"}
+
+// normalizeDifficulty accepts easy/medium/hard (or e/m/h) in any case and
+// returns the capitalized form stored in the database.
+func normalizeDifficulty(difficulty string) (string, error) {
+	d := strings.ToLower(strings.TrimSpace(difficulty))
+	if val, ok := shortToLong[d]; ok {
+		d = val
+	}
+	switch d {
+	case "easy", "medium", "hard":
+		return strings.ToUpper(d[:1]) + d[1:], nil
+	}
+	return "", fmt.Errorf("invalid difficulty %q (use easy, medium or hard)", difficulty)
+}
+
+func normalizeSource(source string) (string, error) {
+	s := strings.ToLower(strings.TrimSpace(source))
+	for _, valid := range validSources {
+		if s == valid {
+			return s, nil
+		}
+	}
+	return "", fmt.Errorf("invalid source %q (use %s)", source, strings.Join(validSources, ", "))
+}
+
+// validateProblem normalizes p in place and checks it against every other
+// problem (excluding p.ID) for duplicate titles and LeetCode numbers.
+func validateProblem(db *sql.DB, p *Problem) error {
+	p.Title = strings.TrimSpace(p.Title)
+	if p.Title == "" {
+		return fmt.Errorf("title is required")
+	}
+
+	var err error
+	if p.Difficulty, err = normalizeDifficulty(p.Difficulty); err != nil {
+		return err
+	}
+	if p.Source == "" {
+		p.Source = sourceLeetCode
+	}
+	if p.Source, err = normalizeSource(p.Source); err != nil {
+		return err
+	}
+
+	if p.LeetcodeNumber < 0 {
+		return fmt.Errorf("LeetCode number must be positive")
+	}
+	if p.LeetcodeNumber > 0 && p.Source != sourceLeetCode {
+		return fmt.Errorf("only LeetCode problems have a LeetCode number")
+	}
+	if p.URL != "" && !strings.HasPrefix(p.URL, "http://") && !strings.HasPrefix(p.URL, "https://") {
+		return fmt.Errorf("URL must start with http:// or https://")
+	}
+
+	var existing string
+	err = db.QueryRow("SELECT title FROM problems WHERE LOWER(title) = LOWER(?) AND id != ?", p.Title, p.ID).Scan(&existing)
+	if err == nil {
+		return fmt.Errorf("a problem titled %q already exists", existing)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("check title: %w", err)
+	}
+
+	if p.LeetcodeNumber > 0 {
+		err = db.QueryRow("SELECT title FROM problems WHERE leetcode_number = ? AND id != ?", p.LeetcodeNumber, p.ID).Scan(&existing)
+		if err == nil {
+			return fmt.Errorf("LC %d is already %q", p.LeetcodeNumber, existing)
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("check LeetCode number: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func addProblem(db *sql.DB, p Problem) (Problem, error) {
+	if err := validateProblem(db, &p); err != nil {
+		return Problem{}, err
+	}
+
+	res, err := db.Exec(`
+		INSERT INTO problems (title, difficulty, grouping, leetcode_number, notes, source, slug, url)
+		VALUES (?, ?, ?, NULLIF(?, 0), NULLIF(?, ''), ?, NULLIF(?, ''), NULLIF(?, ''))
+	`, p.Title, p.Difficulty, p.Grouping, p.LeetcodeNumber, p.Notes, p.Source, p.Slug, p.URL)
+	if err != nil {
+		return Problem{}, fmt.Errorf("insert problem: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return Problem{}, err
+	}
+	p.ID = int(id)
+	return p, nil
+}
+
+func updateProblem(db *sql.DB, p Problem) error {
+	if err := validateProblem(db, &p); err != nil {
+		return err
+	}
+
+	_, err := db.Exec(`
+		UPDATE problems
+		SET title = ?, difficulty = ?, grouping = ?, leetcode_number = NULLIF(?, 0), notes = NULLIF(?, ''),
+			source = ?, slug = NULLIF(?, ''), url = NULLIF(?, '')
+		WHERE id = ?
+	`, p.Title, p.Difficulty, p.Grouping, p.LeetcodeNumber, p.Notes, p.Source, p.Slug, p.URL, p.ID)
+	if err != nil {
+		return fmt.Errorf("update problem: %w", err)
+	}
+	return nil
+}
+
+func countCompletions(db *sql.DB, problemID int) (int, error) {
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM completions WHERE problem_id = ?", problemID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count completions: %w", err)
+	}
+	return count, nil
+}
+
+// deleteProblem removes a problem along with its completions, tags,
+// company data, cached statement, hints, relations, drill history and quiz card.
+func deleteProblem(db *sql.DB, problemID int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{"completions", "problem_tags", "problem_companies", "statements", "hints", "related_problems", "drill_reviews",
+		"complexities", "quiz_reviews"} {
+		if _, err := tx.Exec("DELETE FROM "+table+" WHERE problem_id = ?", problemID); err != nil {
+			return fmt.Errorf("delete from %s: %w", table, err)
+		}
+	}
+	if _, err := tx.Exec("DELETE FROM related_problems WHERE related_id = ?", problemID); err != nil {
+		return fmt.Errorf("delete from related_problems: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM problems WHERE id = ?", problemID); err != nil {
+		return fmt.Errorf("delete problem: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM tags WHERE id NOT IN (SELECT tag_id FROM problem_tags)"); err != nil {
+		return fmt.Errorf("prune tags: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// problemFlags returns a flag set named name for the editable Problem
+// fields, with p's current values as defaults.
+func problemFlags(name string, p *Problem) *flag.FlagSet {
+	fs := newFlagSet(name)
+	fs.StringVar(&p.Title, "title", p.Title, "Problem title")
+	fs.StringVar(&p.Difficulty, "difficulty", p.Difficulty, "Difficulty (easy, medium, hard OR e, m, h)")
+	fs.StringVar(&p.Difficulty, "d", p.Difficulty, "Short for difficulty")
+	fs.StringVar(&p.Grouping, "grouping", p.Grouping, "Topic grouping, e.g. \"Two Pointers\"")
+	fs.StringVar(&p.Grouping, "g", p.Grouping, "Short for grouping")
+	fs.IntVar(&p.LeetcodeNumber, "number", p.LeetcodeNumber, "LeetCode problem number")
+	fs.IntVar(&p.LeetcodeNumber, "n", p.LeetcodeNumber, "Short for number")
+	fs.StringVar(&p.Source, "source", p.Source, "Where the problem comes from ("+strings.Join(validSources, ", ")+")")
+	fs.StringVar(&p.Notes, "notes", p.Notes, "Free-form notes")
+	fs.StringVar(&p.Slug, "slug", p.Slug, "LeetCode URL slug (derived from the title by default)")
+	fs.StringVar(&p.URL, "url", p.URL, "Full problem URL, overriding the LeetCode link")
+	return fs
+}
+
+func problemDeleteFlags(force *bool) *flag.FlagSet {
+	fs := newFlagSet("problem delete")
+	fs.BoolVar(force, "force", false, "Also delete the problem's completion history")
+	return fs
+}
+
+func problemCommandWithDB(db *sql.DB, args []string) error {
+	usage := fmt.Errorf("usage: problem add --title <title> --difficulty <d> [flags], problem edit <problem> [flags], or problem delete <problem> [--force]")
+	if len(args) == 0 {
+		return usage
+	}
+
+	switch args[0] {
+	case "add":
+		p := Problem{Source: sourceLeetCode}
+		fs := problemFlags("problem add", &p)
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() > 0 {
+			return fmt.Errorf("unexpected argument %q (quote titles with spaces)", fs.Arg(0))
+		}
+
+		added, err := addProblem(db, p)
+		if err != nil {
+			return err
+		}
+		fmt.Println(success("Added [%s] %s (%s)", added.Ref(), added.Title, added.Difficulty))
+		return nil
+
+	case "edit":
+		// Parse once to find the problem, then again on top of its current values
+		var scratch Problem
+		probe := problemFlags("problem edit", &scratch)
+		positional, err := parseInterspersed(probe, args[1:])
+		if err != nil {
+			return err
+		}
+		if len(positional) == 0 {
+			return usage
+		}
+		if probe.NFlag() == 0 {
+			return fmt.Errorf("nothing to change; pass --title, --difficulty, --grouping, --number, --source, --notes, --slug or --url")
+		}
+
+		p, err := resolveProblem(db, strings.Join(positional, " "))
+		if err != nil {
+			return err
+		}
+		if _, err := parseInterspersed(problemFlags("problem edit", &p), args[1:]); err != nil {
+			return err
+		}
+
+		if err := updateProblem(db, p); err != nil {
+			return err
+		}
+		fmt.Println(success("Updated '%s'", p.Title))
+		return nil
+
+	case "delete", "rm":
+		var force bool
+		positional, err := parseInterspersed(problemDeleteFlags(&force), args[1:])
+		if err != nil {
+			return err
+		}
+		if len(positional) == 0 {
+			return usage
+		}
+
+		p, err := resolveProblem(db, strings.Join(positional, " "))
+		if err != nil {
+			return err
+		}
+		completions, err := countCompletions(db, p.ID)
+		if err != nil {
+			return err
+		}
+		if completions > 0 && !force {
+			return fmt.Errorf("'%s' has %d completions; rerun with --force to delete them too, or 'retire' it instead", p.Title, completions)
+		}
+
+		if err := deleteProblem(db, p.ID); err != nil {
+			return err
+		}
+		fmt.Println(success("Deleted '%s'", p.Title))
+		return nil
+	}
+
+	return usage
+}
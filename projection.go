@@ -0,0 +1,168 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+// ==================== Completion Projection ====================
+
+const (
+	graduationRepetitions = 3    // Consecutive successful solves before a problem counts as done
+	maxProjectionDays     = 3650 // Give up simulating after ten years
+	projectionTrials      = 200
+)
+
+// simProblem is a problem's SM-2 state inside the projection simulation.
+type simProblem struct {
+	ef       float64
+	interval int
+	reps     int
+	due      int
+	started  bool
+	// Set the first time reps reaches graduationRepetitions and never
+	// cleared, so a later Hard rating doesn't undo the problem being done
+	graduated bool
+}
+
+// projectCompletion fills the projection fields of stats by simulating the
+// study queue with the throughput and rating mix seen over stats.HistoryDays.
+func projectCompletion(db *sql.DB, stats *OverallStats, filter ProblemFilter) error {
+	throughput, err := getAverageThroughput(db, stats.HistoryDays)
+	if err != nil {
+		return err
+	}
+	mix, err := getRatingMix(db, stats.HistoryDays)
+	if err != nil {
+		return err
+	}
+
+	stats.FromHistory = throughput > 0
+	if !stats.FromHistory {
+		throughput = float64(settings.ProblemsPerDay)
+		mix = RatingMix{Easy: 1}
+	}
+	stats.Throughput = throughput
+	stats.Mix = mix
+
+	states, err := getScheduleStates(db, filter)
+	if err != nil {
+		return err
+	}
+
+	rng := rand.New(rand.NewPCG(1, 2))
+	results := make([]int, projectionTrials)
+	for i := range results {
+		results[i] = simulateCompletionDays(rng, mix, throughput, stats.RemainingProblems, states)
+	}
+	slices.Sort(results)
+
+	stats.P50Days = percentile(results, 50)
+	stats.P90Days = percentile(results, 90)
+	stats.P50CompletionAt = projectedDate(stats.P50Days)
+	stats.P90CompletionAt = projectedDate(stats.P90Days)
+	return nil
+}
+
+// simulateCompletionDays runs one trial and returns the number of days until
+// every problem has graduated at least once, or math.MaxInt if that never
+// happens. Graduated problems leave the simulated queue, so their later
+// reviews don't hold back the rest. Each day
+// due reviews are worked oldest first, then new problems, mirroring
+// buildStudyQuery.
+func simulateCompletionDays(rng *rand.Rand, mix RatingMix, throughput float64, newProblems int, states []scheduleState) int {
+	problems := make([]simProblem, 0, len(states)+newProblems)
+	for _, s := range states {
+		problems = append(problems, simProblem{
+			ef: s.EasinessFactor, interval: s.Interval, reps: s.Repetitions,
+			due: max(s.DaysUntil, 0), started: true, graduated: s.Repetitions >= graduationRepetitions,
+		})
+	}
+	for range newProblems {
+		problems = append(problems, simProblem{ef: settings.InitialEF, interval: 1})
+	}
+
+	solve := func(p *simProblem, day int) {
+		p.interval, p.ef, p.reps = calculateSM2(mix.sample(rng), 0, p.ef, p.interval, p.reps)
+		p.due = day + p.interval
+		p.started = true
+		if p.reps >= graduationRepetitions {
+			p.graduated = true
+		}
+	}
+
+	capacity := 0.0
+	var due []*simProblem
+	for day := range maxProjectionDays {
+		if allGraduated(problems) {
+			return day
+		}
+
+		capacity += throughput
+
+		due = due[:0]
+		for i := range problems {
+			if problems[i].started && !problems[i].graduated && problems[i].due <= day {
+				due = append(due, &problems[i])
+			}
+		}
+		slices.SortFunc(due, func(a, b *simProblem) int { return a.due - b.due })
+		for _, p := range due {
+			if capacity < 1 {
+				break
+			}
+			solve(p, day)
+			capacity--
+		}
+
+		for i := range problems {
+			if capacity < 1 {
+				break
+			}
+			if !problems[i].started {
+				solve(&problems[i], day)
+				capacity--
+			}
+		}
+
+		// Unused capacity doesn't carry over to the next day
+		capacity = math.Mod(capacity, 1)
+	}
+
+	return math.MaxInt
+}
+
+func allGraduated(problems []simProblem) bool {
+	for _, p := range problems {
+		if !p.graduated {
+			return false
+		}
+	}
+	return true
+}
+
+func percentile(sorted []int, p int) int {
+	v := sorted[(len(sorted)-1)*p/100]
+	if v == math.MaxInt {
+		return -1
+	}
+	return v
+}
+
+func projectedDate(days int) string {
+	if days < 0 {
+		return ""
+	}
+	return time.Now().AddDate(0, 0, days).Format("Jan 2, 2006")
+}
+
+func formatProjection(days int, date string) string {
+	if days < 0 {
+		return fmt.Sprintf("more than %d years at this pace", maxProjectionDays/365)
+	}
+	return fmt.Sprintf("%s (%d days)", date, days)
+}
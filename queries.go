@@ -2,21 +2,98 @@ package main
 
 import (
 	"database/sql"
+	"errors"
+	"flag"
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 )
 
 // ==================== Problem Queries ====================
 
-func selectStudyProblems(db *sql.DB, difficulty string, count int) ([]Problem, error) {
-	query := buildStudyQuery(difficulty)
+// Problem states. Only active problems are scheduled; suspended and retired
+// problems stay out of rotation until restored.
+const (
+	stateActive    = "active"
+	stateSuspended = "suspended"
+	stateRetired   = "retired"
+)
+
+// eligibleProblemFilter matches problems that study may pick today.
+const eligibleProblemFilter = `p.state = 'active' AND (p.buried_until IS NULL OR date(p.buried_until) <= date('now'))`
+
+// execer is satisfied by both *sql.DB and *sql.Tx.
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// rowQuerier is satisfied by both *sql.DB and *sql.Tx, so lookups inside a
+// transaction can go through it.
+type rowQuerier interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// ProblemFilter narrows problem queries. Empty fields (or "any" for
+// difficulty) match everything.
+type ProblemFilter struct {
+	Difficulty string
+	Tag        string
+	Company    string
+	Topic      string // Part of the grouping, e.g. "graph" for Graphs and Advanced Graphs
+}
+
+// filterFlags registers the --difficulty and --tag filters shared by the
+// listing commands. Difficulty defaults to f.Difficulty if set, else any.
+func filterFlags(fs *flag.FlagSet, f *ProblemFilter) {
+	difficulty := f.Difficulty
+	if difficulty == "" {
+		difficulty = "any"
+	}
+	fs.StringVar(&f.Difficulty, "difficulty", difficulty, "Filter by difficulty (easy, medium, hard, any OR e, m, h, a)")
+	fs.StringVar(&f.Difficulty, "d", difficulty, "Short for difficulty")
+	fs.StringVar(&f.Tag, "tag", "", "Only problems with this tag")
+	fs.StringVar(&f.Tag, "t", "", "Short for tag")
+}
+
+// where returns SQL conditions for the filter, each prefixed with " AND ",
+// against a problems table aliased as p.
+func (f ProblemFilter) where() (string, []any) {
+	var clause string
+	var args []any
+
+	if f.Difficulty != "" && f.Difficulty != "any" {
+		clause += " AND LOWER(p.difficulty) = LOWER(?)"
+		args = append(args, f.Difficulty)
+	}
+	if f.Tag != "" {
+		clause += ` AND EXISTS (
+			SELECT 1 FROM problem_tags pt
+			INNER JOIN tags t ON t.id = pt.tag_id
+			WHERE pt.problem_id = p.id AND t.name = ?
+		)`
+		args = append(args, normalizeTag(f.Tag))
+	}
+	if f.Company != "" {
+		clause += " AND EXISTS (SELECT 1 FROM problem_companies pc WHERE pc.problem_id = p.id AND pc.company = ?)"
+		args = append(args, normalizeCompany(f.Company))
+	}
+	if f.Topic != "" {
+		clause += " AND INSTR(LOWER(p.grouping), LOWER(?)) > 0"
+		args = append(args, strings.TrimSpace(f.Topic))
+	}
 
-	var rows *sql.Rows
-	var err error
-	if difficulty == "any" {
-		rows, err = db.Query(query, count)
-	} else {
-		rows, err = db.Query(query, difficulty, count)
+	return clause, args
+}
+
+func selectStudyProblems(db *sql.DB, filter ProblemFilter, count int) ([]Problem, error) {
+	if filter.Company != "" {
+		return selectCompanyStudyProblems(db, filter, count)
 	}
+
+	query, args := buildStudyQuery(filter)
+
+	rows, err := db.Query(query, append(args, count)...)
 	if err != nil {
 		return nil, err
 	}
@@ -24,8 +101,8 @@ func selectStudyProblems(db *sql.DB, difficulty string, count int) ([]Problem, e
 
 	var problems []Problem
 	for rows.Next() {
-		var p Problem
-		if err := rows.Scan(&p.Title, &p.Difficulty, &p.Grouping, &p.LeetcodeNumber); err != nil {
+		p, err := scanProblem(rows)
+		if err != nil {
 			return nil, fmt.Errorf("scan problem: %w", err)
 		}
 		problems = append(problems, p)
@@ -38,24 +115,23 @@ func selectStudyProblems(db *sql.DB, difficulty string, count int) ([]Problem, e
 	return problems, nil
 }
 
-func buildStudyQuery(difficulty string) string {
+func buildStudyQuery(filter ProblemFilter) (string, []any) {
 	// Prioritizes:
 	// 1. Reviews due today or past (next_review_date <= now) - oldest first
 	// 2. Never attempted problems (new)
 	// 3. Reviews upcoming (next_review_date > now) - nearest first
 	query := `
-		SELECT p.title, p.difficulty, p.grouping, p.leetcode_number
+		SELECT ` + problemColumns + `
 		FROM problems p
 		LEFT JOIN (
 			SELECT problem_id, MAX(completed_at) as last_completion, next_review_date
 			FROM completions
 			GROUP BY problem_id
 		) c ON p.id = c.problem_id
-	`
+		WHERE ` + eligibleProblemFilter
 
-	if difficulty != "any" {
-		query += " WHERE LOWER(p.difficulty) = LOWER(?)"
-	}
+	clause, args := filter.where()
+	query += clause
 
 	query += ` ORDER BY
 		CASE
@@ -69,5 +145,212 @@ func buildStudyQuery(difficulty string) string {
 		END ASC,
 		RANDOM()
 		LIMIT ?`
-	return query
+	return query, args
+}
+
+// problemColumns selects every Problem field; pair it with scanProblem.
+const problemColumns = `p.id, p.title, COALESCE(p.difficulty, ''), COALESCE(p.grouping, ''),
+	COALESCE(p.leetcode_number, 0), COALESCE(p.notes, ''), p.source,
+	COALESCE(p.slug, ''), COALESCE(p.url, '')`
+
+// scanProblem scans problemColumns followed by any extra selected columns.
+func scanProblem(row interface{ Scan(...any) error }, extra ...any) (Problem, error) {
+	var p Problem
+	dest := append([]any{&p.ID, &p.Title, &p.Difficulty, &p.Grouping, &p.LeetcodeNumber, &p.Notes, &p.Source,
+		&p.Slug, &p.URL}, extra...)
+	err := row.Scan(dest...)
+	return p, err
+}
+
+func getAllProblems(db *sql.DB) ([]Problem, error) {
+	rows, err := db.Query("SELECT " + problemColumns + " FROM problems p ORDER BY p.id")
+	if err != nil {
+		return nil, fmt.Errorf("query problems: %w", err)
+	}
+	defer rows.Close()
+
+	var problems []Problem
+	for rows.Next() {
+		p, err := scanProblem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan problem: %w", err)
+		}
+		problems = append(problems, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate rows: %w", err)
+	}
+	return problems, nil
+}
+
+// lookupProblemExact finds a problem by LeetCode number ("1", "LC 1", "#1")
+// or case-insensitive title, reporting whether one was found.
+func lookupProblemExact(db rowQuerier, query string) (Problem, bool, error) {
+	numStr := strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(strings.ToLower(query), "lc"), "#"))
+	if num, err := strconv.Atoi(numStr); err == nil {
+		row := db.QueryRow("SELECT "+problemColumns+" FROM problems p WHERE p.leetcode_number = ?", num)
+		p, err := scanProblem(row)
+		if err == nil {
+			return p, true, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return Problem{}, false, fmt.Errorf("find problem: %w", err)
+		}
+	}
+
+	row := db.QueryRow("SELECT "+problemColumns+" FROM problems p WHERE LOWER(p.title) = LOWER(?)", query)
+	p, err := scanProblem(row)
+	if err == nil {
+		return p, true, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return Problem{}, false, fmt.Errorf("find problem: %w", err)
+	}
+	return Problem{}, false, nil
+}
+
+// resolveProblem finds a problem by LeetCode number ("1", "LC 1", "#1"),
+// exact title, or fuzzy title match. Ambiguous fuzzy matches are an error
+// listing the candidates.
+func resolveProblem(db *sql.DB, query string) (Problem, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return Problem{}, fmt.Errorf("no problem given (use a title or LeetCode number)")
+	}
+
+	if p, ok, err := lookupProblemExact(db, query); err != nil || ok {
+		return p, err
+	}
+
+	problems, err := getAllProblems(db)
+	if err != nil {
+		return Problem{}, err
+	}
+
+	type candidate struct {
+		problem Problem
+		score   int
+	}
+	var candidates []candidate
+	for _, p := range problems {
+		if score := fuzzyScore(query, p.Title); score >= 0 {
+			candidates = append(candidates, candidate{p, score})
+		}
+	}
+	if len(candidates) == 0 {
+		return Problem{}, fmt.Errorf("no problem matches %q", query)
+	}
+
+	slices.SortStableFunc(candidates, func(a, b candidate) int { return b.score - a.score })
+	bestTier := candidates[0].score / 100
+
+	var matches []string
+	for _, c := range candidates {
+		if c.score/100 != bestTier {
+			break
+		}
+		matches = append(matches, c.problem.Title)
+	}
+	if len(matches) == 1 {
+		return candidates[0].problem, nil
+	}
+
+	if len(matches) > 5 {
+		matches = append(matches[:5], "...")
+	}
+	return Problem{}, fmt.Errorf("%q matches several problems: %s", query, strings.Join(matches, ", "))
+}
+
+// ==================== Settings Queries ====================
+
+func getSetting(db *sql.DB, key string) (string, bool, error) {
+	var value string
+	err := db.QueryRow("SELECT value FROM settings WHERE key = ?", key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("get setting %q: %w", key, err)
+	}
+	return value, true, nil
+}
+
+func setSetting(db *sql.DB, key, value string) error {
+	_, err := db.Exec(`
+		INSERT INTO settings (key, value) VALUES (?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value
+	`, key, value)
+	if err != nil {
+		return fmt.Errorf("set setting %q: %w", key, err)
+	}
+	return nil
+}
+
+func deleteSetting(db *sql.DB, key string) error {
+	if _, err := db.Exec("DELETE FROM settings WHERE key = ?", key); err != nil {
+		return fmt.Errorf("delete setting %q: %w", key, err)
+	}
+	return nil
+}
+
+// ==================== History Queries ====================
+
+// RatingMix is the share of Easy/Medium/Hard effort ratings over a window.
+type RatingMix struct {
+	Easy    float64
+	Medium  float64
+	Hard    float64
+	Samples int
+}
+
+func getRatingMix(db *sql.DB, days int) (RatingMix, error) {
+	rows, err := db.Query(`
+		SELECT effort_rating, COUNT(*)
+		FROM completions
+		WHERE completed_at >= datetime('now', '-' || ? || ' days')
+		GROUP BY effort_rating
+	`, days)
+	if err != nil {
+		return RatingMix{}, fmt.Errorf("query rating mix: %w", err)
+	}
+	defer rows.Close()
+
+	counts := map[int]int{}
+	var mix RatingMix
+	for rows.Next() {
+		var rating, count int
+		if err := rows.Scan(&rating, &count); err != nil {
+			return RatingMix{}, fmt.Errorf("scan rating mix: %w", err)
+		}
+		counts[rating] = count
+		mix.Samples += count
+	}
+	if err := rows.Err(); err != nil {
+		return RatingMix{}, fmt.Errorf("iterate rows: %w", err)
+	}
+
+	if mix.Samples == 0 {
+		// No history yet - assume everything goes smoothly
+		return RatingMix{Easy: 1}, nil
+	}
+
+	total := float64(mix.Samples)
+	mix.Easy = float64(counts[1]) / total
+	mix.Medium = float64(counts[2]) / total
+	mix.Hard = float64(counts[3]) / total
+	return mix, nil
+}
+
+// getAverageThroughput returns completions per day over the last `days` days.
+func getAverageThroughput(db *sql.DB, days int) (float64, error) {
+	var count int
+	err := db.QueryRow(`
+		SELECT COUNT(*)
+		FROM completions
+		WHERE completed_at >= datetime('now', '-' || ? || ' days')
+	`, days).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("query throughput: %w", err)
+	}
+	return float64(count) / float64(days), nil
 }
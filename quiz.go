@@ -0,0 +1,294 @@
+package main
+
+import (
+	"bufio"
+	"database/sql"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// ==================== Complexity Quiz ====================
+
+// Complexity is the optimal time and space complexity of a problem, in
+// big-O notation.
+type Complexity struct {
+	Time  string `json:"time"`
+	Space string `json:"space"`
+}
+
+type QuizCard struct {
+	Problem
+	Complexity
+	EF       float64
+	Interval int
+	Reps     int
+}
+
+// normalizeComplexity reduces an answer to a canonical form so "O(n log n)",
+// "nlogn" and "O(N * log(N))" all compare equal.
+func normalizeComplexity(s string) string {
+	s = strings.ToLower(strings.Join(strings.Fields(s), ""))
+	if strings.HasPrefix(s, "o(") && strings.HasSuffix(s, ")") {
+		s = s[2 : len(s)-1]
+	}
+	return strings.NewReplacer(
+		"(", "",
+		")", "",
+		"*", "",
+		"·", "",
+		"²", "^2",
+		"³", "^3",
+	).Replace(s)
+}
+
+func complexityMatches(answer, expected string) bool {
+	return answer != "" && normalizeComplexity(answer) == normalizeComplexity(expected)
+}
+
+// quizRating maps a quiz result onto the effort ratings calculateSM2 takes:
+// both right is Easy, one right Medium, neither Hard.
+func quizRating(timeCorrect, spaceCorrect bool) int {
+	switch {
+	case timeCorrect && spaceCorrect:
+		return 1
+	case timeCorrect || spaceCorrect:
+		return 2
+	}
+	return 3
+}
+
+func setComplexity(db execer, problemID int, c Complexity) error {
+	c.Time, c.Space = strings.TrimSpace(c.Time), strings.TrimSpace(c.Space)
+	if c.Time == "" || c.Space == "" {
+		return fmt.Errorf("both time and space complexity are required")
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO complexities (problem_id, time, space)
+		VALUES (?, ?, ?)
+		ON CONFLICT(problem_id) DO UPDATE SET
+			time = excluded.time,
+			space = excluded.space
+	`, problemID, c.Time, c.Space)
+	if err != nil {
+		return fmt.Errorf("save complexity: %w", err)
+	}
+	return nil
+}
+
+// selectQuizCards returns quiz cards that are due (most overdue first),
+// topped up with never-quizzed ones in random order. Only problems with a
+// stored complexity have cards.
+func selectQuizCards(db *sql.DB, filter ProblemFilter, count int) ([]QuizCard, error) {
+	clause, args := filter.where()
+	rows, err := db.Query(`
+		SELECT `+problemColumns+`, x.time, x.space,
+			COALESCE(q.easiness_factor, ?), COALESCE(q.interval_days, 1), COALESCE(q.repetitions, 0)
+		FROM problems p
+		INNER JOIN complexities x ON x.problem_id = p.id
+		LEFT JOIN (
+			SELECT problem_id, MAX(answered_at), easiness_factor, interval_days, repetitions, next_review_date
+			FROM quiz_reviews
+			GROUP BY problem_id
+		) q ON p.id = q.problem_id
+		WHERE `+eligibleProblemFilter+clause+`
+			AND (q.next_review_date IS NULL OR date(q.next_review_date) <= date('now'))
+		ORDER BY q.next_review_date IS NULL, q.next_review_date, RANDOM()
+		LIMIT ?
+	`, append(append([]any{settings.InitialEF}, args...), count)...)
+	if err != nil {
+		return nil, fmt.Errorf("query quiz cards: %w", err)
+	}
+	defer rows.Close()
+
+	var cards []QuizCard
+	for rows.Next() {
+		var c QuizCard
+		var err error
+		c.Problem, err = scanProblem(rows, &c.Time, &c.Space, &c.EF, &c.Interval, &c.Reps)
+		if err != nil {
+			return nil, fmt.Errorf("scan quiz card: %w", err)
+		}
+		cards = append(cards, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate rows: %w", err)
+	}
+	return cards, nil
+}
+
+// recordQuizAnswer schedules a card with SM-2, independently of the
+// problem's own solve schedule.
+func recordQuizAnswer(db *sql.DB, card QuizCard, timeAnswer, spaceAnswer string, timeCorrect, spaceCorrect bool) (int, error) {
+	rating := quizRating(timeCorrect, spaceCorrect)
+	interval, ef, reps := calculateSM2(rating, 0, card.EF, card.Interval, card.Reps)
+
+	err := session.record(func() error {
+		_, err := db.Exec(`
+			INSERT INTO quiz_reviews (problem_id, time_answer, space_answer, time_correct, space_correct,
+				effort_rating, interval_days, easiness_factor, repetitions, next_review_date)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now', '+' || ? || ' days'))
+		`, card.ID, timeAnswer, spaceAnswer, timeCorrect, spaceCorrect, rating, interval, ef, reps, interval)
+		if err != nil {
+			return fmt.Errorf("record quiz answer: %w", err)
+		}
+		session.quizzed++
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return interval, nil
+}
+
+// askComplexity reads one answer and grades it, letting the user overrule a
+// mismatch that is only a difference in notation.
+func askComplexity(reader *bufio.Reader, label, expected string) (answer string, correct bool, err error) {
+	fmt.Printf("  %s complexity: ", label)
+	if answer, err = reader.ReadString('\n'); err != nil {
+		return "", false, err
+	}
+	answer = strings.TrimSpace(answer)
+
+	if complexityMatches(answer, expected) {
+		fmt.Println("  " + success("Correct!"))
+		return answer, true, nil
+	}
+
+	fmt.Println("  " + failure("Expected %s", expected))
+	if answer == "" {
+		return answer, false, nil
+	}
+	fmt.Print("  Count it as correct anyway? (y/n): ")
+	override, err := reader.ReadString('\n')
+	if err != nil {
+		return "", false, err
+	}
+	override = strings.TrimSpace(strings.ToLower(override))
+	return answer, override == "y" || override == "yes", nil
+}
+
+type quizOptions struct {
+	Filter ProblemFilter
+	Count  int
+}
+
+func quizFlags(o *quizOptions) *flag.FlagSet {
+	fs := newFlagSet("quiz")
+	filterFlags(fs, &o.Filter)
+	fs.IntVar(&o.Count, "count", 5, "Number of cards")
+	fs.IntVar(&o.Count, "c", 5, "Short for count")
+	return fs
+}
+
+func quizSetFlags(c *Complexity) *flag.FlagSet {
+	fs := newFlagSet("quiz set")
+	fs.StringVar(&c.Time, "time", "", "Optimal time complexity, e.g. \"O(n log n)\"")
+	fs.StringVar(&c.Space, "space", "", "Optimal space complexity, e.g. \"O(1)\"")
+	return fs
+}
+
+func quizCommandWithDB(db *sql.DB, args []string) error {
+	usage := fmt.Errorf("usage: quiz [-c n] [-d difficulty] [--tag tag], quiz set <problem> --time <O(..)> --space <O(..)>, or quiz clear <problem>")
+
+	if len(args) > 0 {
+		switch args[0] {
+		case "set":
+			var c Complexity
+			positional, err := parseInterspersed(quizSetFlags(&c), args[1:])
+			if err != nil {
+				return err
+			}
+			if len(positional) == 0 {
+				return usage
+			}
+			problem, err := resolveProblem(db, strings.Join(positional, " "))
+			if err != nil {
+				return err
+			}
+			if err := setComplexity(db, problem.ID, c); err != nil {
+				return err
+			}
+			fmt.Println(success("'%s' is %s time, %s space", problem.Title, c.Time, c.Space))
+			return nil
+
+		case "clear":
+			if len(args) < 2 {
+				return usage
+			}
+			problem, err := resolveProblem(db, strings.Join(args[1:], " "))
+			if err != nil {
+				return err
+			}
+			tx, err := db.Begin()
+			if err != nil {
+				return fmt.Errorf("begin transaction: %w", err)
+			}
+			defer tx.Rollback()
+			for _, table := range []string{"complexities", "quiz_reviews"} {
+				if _, err := tx.Exec("DELETE FROM "+table+" WHERE problem_id = ?", problem.ID); err != nil {
+					return fmt.Errorf("delete from %s: %w", table, err)
+				}
+			}
+			if err := tx.Commit(); err != nil {
+				return fmt.Errorf("commit transaction: %w", err)
+			}
+			fmt.Println(success("Removed the quiz card for '%s'", problem.Title))
+			return nil
+		}
+	}
+
+	var opts quizOptions
+	fs := quizFlags(&opts)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usage
+	}
+	if !interactive {
+		return fmt.Errorf("quiz asks for answers, so it needs an interactive terminal")
+	}
+	if val, ok := shortToLong[opts.Filter.Difficulty]; ok {
+		opts.Filter.Difficulty = val
+	}
+
+	cards, err := selectQuizCards(db, opts.Filter, opts.Count)
+	if err != nil {
+		return err
+	}
+	if len(cards) == 0 {
+		fmt.Println("\nNo quiz cards due. Add answers with 'quiz set <problem> --time .. --space ..'.")
+		return nil
+	}
+
+	fmt.Println("\n" + theme.icon("🧮") + "Complexity Quiz: give the optimal time and space complexity")
+	fmt.Println("========================")
+
+	reader := stdin
+	score := 0
+	for i, card := range cards {
+		fmt.Printf("\n%d/%d. [%s] %s (%s)\n", i+1, len(cards), card.Ref(), card.Title, card.Difficulty)
+		timeAnswer, timeCorrect, err := askComplexity(reader, "Time", card.Time)
+		if err != nil {
+			return err
+		}
+		spaceAnswer, spaceCorrect, err := askComplexity(reader, "Space", card.Space)
+		if err != nil {
+			return err
+		}
+
+		interval, err := recordQuizAnswer(db, card, timeAnswer, spaceAnswer, timeCorrect, spaceCorrect)
+		if err != nil {
+			return err
+		}
+		if timeCorrect && spaceCorrect {
+			score++
+		}
+		fmt.Printf("  Next quiz in %d days\n", interval)
+	}
+
+	fmt.Printf("\nScore: %d/%d fully correct\n\n", score, len(cards))
+	return nil
+}
@@ -0,0 +1,470 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/term"
+)
+
+// ==================== Line Editing ====================
+
+const (
+	historyFile    = ".gostudy_history"
+	historyLimit   = 500
+	maxCompletions = 20
+)
+
+// errInterrupted is returned by ReadLine when Ctrl-C cancels the line, and
+// by reads from stdin when it cancels the running command.
+var errInterrupted = errors.New("interrupted")
+
+// stdin is shared by the REPL and every interactive prompt. The line editor
+// takes from it a byte at a time (see interruptReader), so answers typed
+// ahead of a prompt are left here for it. Lines pasted while the REPL is
+// editing still arrive in raw mode, ending in \r rather than \n, so a prompt
+// that follows sees them as one line.
+var stdin = bufio.NewReader(stdinSource)
+
+// stdinSource reads os.Stdin on its own goroutine, so a prompt waiting for
+// input can give up when Ctrl-C cancels the command that asked.
+var stdinSource = newCancelableReader(os.Stdin)
+
+// cancelableReader passes on what its goroutine reads. Once cancel is called
+// Read returns errInterrupted until clearCancel, and nothing read is lost.
+type cancelableReader struct {
+	chunks  chan []byte
+	err     error // Set before chunks is closed
+	pending []byte
+
+	mu        sync.Mutex
+	cancelled chan struct{} // Closed by cancel
+}
+
+func newCancelableReader(r io.Reader) *cancelableReader {
+	cr := &cancelableReader{chunks: make(chan []byte), cancelled: make(chan struct{})}
+	go func() {
+		for {
+			buf := make([]byte, 4096)
+			n, err := r.Read(buf)
+			if n > 0 {
+				cr.chunks <- buf[:n]
+			}
+			if err != nil {
+				cr.err = err
+				close(cr.chunks)
+				return
+			}
+		}
+	}()
+	return cr
+}
+
+func (cr *cancelableReader) Read(p []byte) (int, error) {
+	if len(cr.pending) == 0 {
+		cr.mu.Lock()
+		cancelled := cr.cancelled
+		cr.mu.Unlock()
+		select {
+		case <-cancelled:
+			return 0, errInterrupted
+		case chunk, ok := <-cr.chunks:
+			if !ok {
+				return 0, cr.err
+			}
+			cr.pending = chunk
+		}
+	}
+	n := copy(p, cr.pending)
+	cr.pending = cr.pending[n:]
+	return n, nil
+}
+
+func (cr *cancelableReader) isCancelled() bool {
+	select {
+	case <-cr.cancelled:
+		return true
+	default:
+		return false
+	}
+}
+
+// cancel interrupts the Read in progress and every one after it.
+func (cr *cancelableReader) cancel() {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	if !cr.isCancelled() {
+		close(cr.cancelled)
+	}
+}
+
+// clearCancel lets reads through again once the cancelled command is over.
+func (cr *cancelableReader) clearCancel() {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	if cr.isCancelled() {
+		cr.cancelled = make(chan struct{})
+	}
+}
+
+// interactive is false when commands come from a pipe, a file or --exec.
+// Prompts are then suppressed, and commands that would ask questions take
+// their answers from flags instead.
+var interactive = isTerminal(os.Stdin)
+
+// commandSubcommands lists the subcommands completed after a command name.
+var commandSubcommands = map[string][]string{
+	"tag":       {"add", "remove", "list"},
+	"company":   {"import", "list"},
+	"problem":   {"add", "edit", "delete"},
+	"statement": {"import"},
+	"hint":      {"set", "show", "clear"},
+	"related":   {"add", "remove"},
+	"quiz":      {"set", "clear"},
+	"drill":     {"stats"},
+	"alias":     {"list", "remove"},
+	"macro":     {"list", "remove"},
+	"config":    {"list", "get", "set", "unset"},
+}
+
+// titleArgs maps commands that take a problem title to the number of words
+// (subcommands, tag names) that come before the title.
+var titleArgs = map[string]int{
+	"show": 0, "done": 0, "open": 0, "search": 0, "suspend": 0, "bury": 0, "retire": 0,
+	"unsuspend": 0, "statement": 0, "related": 0,
+	"hint": 1, "quiz": 1, "problem": 1, "company": 1,
+	"tag": 2,
+}
+
+// fileHistory keeps the most recent lines in memory, newest first, and
+// appends every new line to the history file.
+type fileHistory struct {
+	path    string
+	entries []string
+}
+
+func loadHistory(path string) *fileHistory {
+	h := &fileHistory{path: path}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return h // No history yet
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	lines = lines[max(0, len(lines)-historyLimit):]
+	for _, line := range slices.Backward(lines) {
+		if line != "" {
+			h.entries = append(h.entries, line)
+		}
+	}
+	return h
+}
+
+func (h *fileHistory) Add(entry string) {
+	if strings.TrimSpace(entry) == "" || (len(h.entries) > 0 && h.entries[0] == entry) {
+		return
+	}
+	h.entries = slices.Insert(h.entries, 0, entry)
+	if len(h.entries) > historyLimit {
+		h.entries = h.entries[:historyLimit]
+	}
+
+	// History is a convenience; failing to save it shouldn't interrupt anything
+	if f, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600); err == nil {
+		fmt.Fprintln(f, entry)
+		f.Close()
+	}
+}
+
+func (h *fileHistory) Len() int          { return len(h.entries) }
+func (h *fileHistory) At(idx int) string { return h.entries[idx] }
+
+// interruptReader notes when Ctrl-C passes through, since term.Terminal
+// reports it as io.EOF just like Ctrl-D. It hands over one byte per Read:
+// term.Terminal buffers whatever it reads, and anything past the end of the
+// line would be lost to the prompts reading stdin after it.
+type interruptReader struct {
+	r           io.Reader
+	interrupted bool
+}
+
+func (ir *interruptReader) Read(p []byte) (int, error) {
+	if len(p) > 1 {
+		p = p[:1]
+	}
+	n, err := ir.r.Read(p)
+	if bytes.IndexByte(p[:n], 3) >= 0 {
+		ir.interrupted = true
+	}
+	return n, err
+}
+
+// lineEditor reads REPL input with arrow-key editing, history and tab
+// completion when stdin is a terminal, and plain lines otherwise.
+type lineEditor struct {
+	db       *sql.DB
+	terminal *term.Terminal
+	input    *interruptReader
+}
+
+// rawMode tracks the terminal state saved by makeRaw, so a signal can put the
+// terminal back no matter which screen switched it to raw mode.
+var rawMode struct {
+	sync.Mutex
+	state   *term.State
+	cleanup string // Written on restore, e.g. to leave the alternate screen
+}
+
+// makeRaw puts stdin into raw mode until restoreTerminal is called. cleanup
+// is written to stdout just before the terminal is restored.
+func makeRaw(cleanup string) error {
+	state, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return err
+	}
+	rawMode.Lock()
+	defer rawMode.Unlock()
+	rawMode.state, rawMode.cleanup = state, cleanup
+	return nil
+}
+
+// restoreTerminal undoes makeRaw. It does nothing when the terminal isn't in
+// raw mode.
+func restoreTerminal() {
+	rawMode.Lock()
+	defer rawMode.Unlock()
+	if rawMode.state == nil {
+		return
+	}
+	os.Stdout.WriteString(rawMode.cleanup)
+	term.Restore(int(os.Stdin.Fd()), rawMode.state)
+	rawMode.state = nil
+}
+
+func newLineEditor(db *sql.DB) *lineEditor {
+	e := &lineEditor{db: db}
+	if !isTerminal(os.Stdin) {
+		return e
+	}
+
+	e.input = &interruptReader{r: stdin}
+	e.reset()
+	if dir, err := dataDir(); err == nil {
+		e.terminal.History = loadHistory(filepath.Join(dir, historyFile))
+	}
+	return e
+}
+
+// reset starts a fresh terminal, keeping the history. term.Terminal keeps
+// the partial line after Ctrl-C, so this is how the line gets discarded.
+func (e *lineEditor) reset() {
+	t := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{e.input, os.Stdout}, "")
+	t.AutoCompleteCallback = e.complete
+	if e.terminal != nil {
+		t.History = e.terminal.History
+	}
+	e.terminal = t
+}
+
+// ReadLine prompts for one line. It returns errInterrupted when Ctrl-C
+// cancels the line and io.EOF at the end of input.
+func (e *lineEditor) ReadLine(prompt string) (string, error) {
+	stdinSource.clearCancel()
+	if e.terminal == nil {
+		// Piped input: no prompt, so the output is just what commands print
+		line, err := stdin.ReadString('\n')
+		if err != nil && line == "" {
+			return "", err
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	// Raw mode only while editing, so command output keeps normal newlines
+	fd := int(os.Stdin.Fd())
+	if err := makeRaw(""); err != nil {
+		return "", fmt.Errorf("enable line editing: %w", err)
+	}
+	defer restoreTerminal()
+
+	if width, height, err := term.GetSize(fd); err == nil && width > 0 {
+		e.terminal.SetSize(width, height)
+	}
+	e.terminal.SetPrompt(prompt)
+	e.input.interrupted = false
+
+	line, err := e.terminal.ReadLine()
+	if errors.Is(err, io.EOF) && e.input.interrupted {
+		os.Stdout.WriteString("^C\r\n")
+		e.reset()
+		return "", errInterrupted
+	}
+	return line, err
+}
+
+// complete handles Tab: it completes the word before the cursor when there is
+// one match, extends it to the longest common prefix of several, and lists
+// them when it can't extend.
+func (e *lineEditor) complete(line string, pos int, key rune) (string, int, bool) {
+	if key != '\t' {
+		return "", 0, false
+	}
+
+	head, tail := line[:pos], line[pos:]
+	start, candidates := e.candidates(head)
+	word := head[start:]
+
+	var matches []string
+	for _, c := range candidates {
+		if len(c) >= len(word) && strings.EqualFold(c[:len(word)], word) {
+			matches = append(matches, c)
+		}
+	}
+	slices.Sort(matches)
+	matches = slices.Compact(matches)
+
+	switch len(matches) {
+	case 0:
+		return line, pos, true
+	case 1:
+		completed := head[:start] + matches[0] + " "
+		return completed + strings.TrimLeft(tail, " "), len(completed), true
+	}
+
+	if prefix := commonPrefix(matches); len(prefix) > len(word) {
+		completed := head[:start] + prefix
+		return completed + tail, len(completed), true
+	}
+
+	shown := matches[:min(len(matches), maxCompletions)]
+	list := strings.Join(shown, "   ")
+	if len(matches) > len(shown) {
+		list += fmt.Sprintf("   ... and %d more", len(matches)-len(shown))
+	}
+	e.terminal.Write([]byte(list + "\n"))
+	return line, pos, true
+}
+
+// candidates returns where the word being completed starts in head and the
+// possible completions for it.
+func (e *lineEditor) candidates(head string) (int, []string) {
+	starts := wordStarts(head)
+	current := len(head)
+	if len(starts) > 0 && !strings.HasSuffix(head, " ") {
+		current = starts[len(starts)-1]
+		starts = starts[:len(starts)-1]
+	}
+
+	// The first word is a command name
+	if len(starts) == 0 {
+		var names []string
+		for name := range getCommands(e.db) {
+			names = append(names, name)
+		}
+		names = slices.AppendSeq(names, maps.Keys(config.Aliases))
+		names = slices.AppendSeq(names, maps.Keys(config.Macros))
+		return current, names
+	}
+
+	cmd := strings.Fields(head)[0]
+	if strings.HasPrefix(head[current:], "-") {
+		return current, flagNames(getCommands(e.db)[cmd])
+	}
+
+	var candidates []string
+	if len(starts) == 1 {
+		candidates = append(candidates, commandSubcommands[cmd]...)
+	}
+
+	skip, takesTitle := titleArgs[cmd]
+	if !takesTitle {
+		return current, candidates
+	}
+
+	// Titles have spaces, so the fragment being completed runs back to the
+	// command's leading words or the last flag and its value
+	words := append(starts, current)
+	isFlag := func(i int) bool { return strings.HasPrefix(head[words[i]:], "-") }
+	last := len(words) - 1
+	first := last + 1
+	for i := last; i > skip; i-- {
+		if isFlag(i) || isFlag(i-1) {
+			break
+		}
+		first = i
+	}
+	if first > last {
+		return current, candidates // Completing a flag's value
+	}
+
+	titles, err := getProblemTitles(e.db)
+	if err != nil {
+		return current, candidates
+	}
+	if first < last {
+		return words[first], titles
+	}
+	return current, append(candidates, titles...)
+}
+
+// wordStarts returns the byte offset of each whitespace-separated word.
+func wordStarts(s string) []int {
+	var starts []int
+	inWord := false
+	for i, r := range s {
+		if r == ' ' || r == '\t' {
+			inWord = false
+		} else if !inWord {
+			starts = append(starts, i)
+			inWord = true
+		}
+	}
+	return starts
+}
+
+// commonPrefix returns the longest case-insensitive common prefix of words,
+// in the casing of the first.
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, w := range words[1:] {
+		n := 0
+		for n < len(prefix) && n < len(w) && strings.EqualFold(prefix[n:n+1], w[n:n+1]) {
+			n++
+		}
+		prefix = prefix[:n]
+	}
+	return prefix
+}
+
+func getProblemTitles(db *sql.DB) ([]string, error) {
+	rows, err := db.Query("SELECT title FROM problems ORDER BY title")
+	if err != nil {
+		return nil, fmt.Errorf("query titles: %w", err)
+	}
+	defer rows.Close()
+
+	var titles []string
+	for rows.Next() {
+		var title string
+		if err := rows.Scan(&title); err != nil {
+			return nil, fmt.Errorf("scan title: %w", err)
+		}
+		titles = append(titles, title)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate rows: %w", err)
+	}
+	return titles, nil
+}
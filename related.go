@@ -0,0 +1,174 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// ==================== Related Problems ====================
+
+// RelatedProblem is a problem linked to another either explicitly (seeded
+// or added with 'related add') or by shared topic and tags.
+type RelatedProblem struct {
+	Problem
+	Linked     bool
+	SameTopic  bool
+	SharedTags int
+}
+
+// Reason describes why the problem is related, for listings.
+func (r RelatedProblem) Reason() string {
+	var reasons []string
+	if r.Linked {
+		reasons = append(reasons, "linked")
+	}
+	if r.SameTopic {
+		reasons = append(reasons, "same topic")
+	}
+	if r.SharedTags == 1 {
+		reasons = append(reasons, "1 shared tag")
+	} else if r.SharedTags > 1 {
+		reasons = append(reasons, fmt.Sprintf("%d shared tags", r.SharedTags))
+	}
+	return strings.Join(reasons, ", ")
+}
+
+// linkProblems relates two problems in both directions.
+func linkProblems(db execer, a, b int) error {
+	if a == b {
+		return fmt.Errorf("a problem cannot be related to itself")
+	}
+	_, err := db.Exec("INSERT OR IGNORE INTO related_problems (problem_id, related_id) VALUES (?, ?), (?, ?)", a, b, b, a)
+	if err != nil {
+		return fmt.Errorf("link problems: %w", err)
+	}
+	return nil
+}
+
+// unlinkProblems removes an explicit relation and reports whether one existed.
+func unlinkProblems(db *sql.DB, a, b int) (bool, error) {
+	res, err := db.Exec(`
+		DELETE FROM related_problems
+		WHERE (problem_id = ? AND related_id = ?) OR (problem_id = ? AND related_id = ?)
+	`, a, b, b, a)
+	if err != nil {
+		return false, fmt.Errorf("unlink problems: %w", err)
+	}
+	removed, err := res.RowsAffected()
+	return removed > 0, err
+}
+
+// getRelatedProblems returns up to limit problems related to p: explicit links
+// first, then by shared tags (each worth two) and shared topic, if p has one.
+// With unseenOnly, only never-attempted problems that study could pick today
+// are returned.
+func getRelatedProblems(db *sql.DB, p Problem, limit int, unseenOnly bool) ([]RelatedProblem, error) {
+	var filter string
+	if unseenOnly {
+		filter = " AND " + eligibleProblemFilter + " AND NOT EXISTS (SELECT 1 FROM completions WHERE problem_id = p.id)"
+	}
+
+	rows, err := db.Query(`
+		SELECT `+problemColumns+`, r.linked, r.same_topic, r.shared_tags
+		FROM (
+			SELECT p.id,
+				EXISTS (SELECT 1 FROM related_problems WHERE problem_id = ? AND related_id = p.id) AS linked,
+				COALESCE(p.grouping <> '' AND p.grouping = ?, 0) AS same_topic,
+				(
+					SELECT COUNT(*)
+					FROM problem_tags a
+					INNER JOIN problem_tags b ON a.tag_id = b.tag_id
+					WHERE a.problem_id = ? AND b.problem_id = p.id
+				) AS shared_tags
+			FROM problems p
+			WHERE p.id != ?`+filter+`
+		) r
+		INNER JOIN problems p ON p.id = r.id
+		WHERE r.linked OR r.same_topic OR r.shared_tags > 0
+		ORDER BY r.linked DESC, r.shared_tags * 2 + r.same_topic DESC, p.id
+		LIMIT ?
+	`, p.ID, p.Grouping, p.ID, p.ID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query related problems: %w", err)
+	}
+	defer rows.Close()
+
+	var related []RelatedProblem
+	for rows.Next() {
+		var r RelatedProblem
+		var err error
+		r.Problem, err = scanProblem(rows, &r.Linked, &r.SameTopic, &r.SharedTags)
+		if err != nil {
+			return nil, fmt.Errorf("scan problem: %w", err)
+		}
+		related = append(related, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate rows: %w", err)
+	}
+	return related, nil
+}
+
+func relatedCommandWithDB(db *sql.DB, args []string) error {
+	usage := fmt.Errorf(`usage: related <problem>, or related add|remove <problem> <problem> (quote titles with spaces, e.g. related add "two sum" 167)`)
+	if len(args) == 0 {
+		return usage
+	}
+
+	switch args[0] {
+	case "add", "remove", "rm":
+		if len(args) != 3 {
+			return usage
+		}
+		a, err := resolveProblem(db, args[1])
+		if err != nil {
+			return err
+		}
+		b, err := resolveProblem(db, args[2])
+		if err != nil {
+			return err
+		}
+
+		if args[0] == "add" {
+			if err := linkProblems(db, a.ID, b.ID); err != nil {
+				return err
+			}
+			fmt.Println(success("Linked '%s' and '%s'", a.Title, b.Title))
+			return nil
+		}
+
+		removed, err := unlinkProblems(db, a.ID, b.ID)
+		if err != nil {
+			return err
+		}
+		if !removed {
+			return fmt.Errorf("'%s' and '%s' are not linked", a.Title, b.Title)
+		}
+		fmt.Println(success("Unlinked '%s' and '%s'", a.Title, b.Title))
+		return nil
+	}
+
+	problem, err := resolveProblem(db, strings.Join(args, " "))
+	if err != nil {
+		return err
+	}
+	related, err := getRelatedProblems(db, problem, 10, false)
+	if err != nil {
+		return err
+	}
+	if len(related) == 0 {
+		fmt.Printf("Nothing related to '%s' yet. Link one with 'related add'.\n", problem.Title)
+		return nil
+	}
+
+	table := listingTable(fmt.Sprintf("\n%sRelated to %s:", theme.icon("🔗"), problem.Title), "Why")
+	lastListing = lastListing[:0]
+	for i, r := range related {
+		table.AddRow(fmt.Sprintf("%d.", i+1), "["+r.Ref()+"]", hyperlink(problemURL(r.Problem), r.Title), r.Difficulty, r.Reason())
+		lastListing = append(lastListing, r.Problem)
+	}
+	table.Print()
+	fmt.Println()
+	return nil
+}
@@ -16,9 +16,41 @@ type ReviewInfo struct {
 	Repetitions     sql.NullInt64
 	EasinessFactor  sql.NullFloat64
 	DaysUntilReview sql.NullInt64
+	Lapses          int // Completions that reset the schedule, not counting a first solve
 }
 
-func getReviewHistory(db *sql.DB, difficulty string) ([]ReviewInfo, error) {
+// reviewSorts are the orders 'review --sort' accepts.
+var reviewSorts = map[string]string{
+	"next":   "c.next_review_date ASC, p.title ASC",
+	"last":   "c.completed_at DESC, p.title ASC",
+	"ef":     "c.easiness_factor ASC, p.title ASC",
+	"lapses": "c.lapses DESC, p.title ASC",
+	"title":  "p.title ASC",
+}
+
+// reviewStatuses are the statuses 'review --status' accepts.
+var reviewStatuses = []string{"overdue", "today", "soon", "future"}
+
+// reviewStatusCondition is the SQL condition on days until the next review
+// for one of reviewStatuses.
+func reviewStatusCondition(status string) string {
+	switch status {
+	case "overdue":
+		return "< 0"
+	case "today":
+		return "= 0"
+	case "soon":
+		return fmt.Sprintf("BETWEEN 1 AND %d", settings.SoonDays)
+	}
+	return fmt.Sprintf("> %d", settings.SoonDays)
+}
+
+const daysUntilReview = `CAST((julianday(date(c.next_review_date)) - julianday(date('now'))) AS INTEGER)`
+
+// getReviewHistory returns the latest completion of each problem matching
+// filter, ordered by one of reviewSorts and, unless status is empty, limited
+// to one of reviewStatuses.
+func getReviewHistory(db *sql.DB, filter ProblemFilter, sortBy, status string) ([]ReviewInfo, error) {
 	query := `
 		SELECT
 			p.title,
@@ -27,7 +59,8 @@ func getReviewHistory(db *sql.DB, difficulty string) ([]ReviewInfo, error) {
 			c.next_review_date,
 			c.repetitions,
 			c.easiness_factor,
-			CAST((julianday(date(c.next_review_date)) - julianday(date('now'))) AS INTEGER) as days_until
+			` + daysUntilReview + ` as days_until,
+			c.lapses
 		FROM problems p
 		LEFT JOIN (
 			SELECT
@@ -35,26 +68,31 @@ func getReviewHistory(db *sql.DB, difficulty string) ([]ReviewInfo, error) {
 				MAX(completed_at) as completed_at,
 				next_review_date,
 				repetitions,
-				easiness_factor
+				easiness_factor,
+				SUM(CASE WHEN repetitions = 0 AND EXISTS (
+					SELECT 1 FROM completions earlier
+					WHERE earlier.problem_id = completions.problem_id
+						AND earlier.completed_at < completions.completed_at
+				) THEN 1 ELSE 0 END) as lapses
 			FROM completions
 			GROUP BY problem_id
 		) c ON p.id = c.problem_id
-		WHERE c.completed_at IS NOT NULL
+		WHERE c.completed_at IS NOT NULL AND p.state = 'active'
 	`
 
-	if difficulty != "any" {
-		query += " AND LOWER(p.difficulty) = LOWER(?)"
+	clause, args := filter.where()
+	query += clause
+	if status != "" {
+		query += " AND " + daysUntilReview + " " + reviewStatusCondition(status)
 	}
 
-	query += " ORDER BY c.next_review_date ASC, p.title ASC"
-
-	var rows *sql.Rows
-	var err error
-	if difficulty == "any" {
-		rows, err = db.Query(query)
-	} else {
-		rows, err = db.Query(query, difficulty)
+	order, ok := reviewSorts[sortBy]
+	if !ok {
+		order = reviewSorts["next"]
 	}
+	query += " ORDER BY " + order
+
+	rows, err := db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -64,7 +102,7 @@ func getReviewHistory(db *sql.DB, difficulty string) ([]ReviewInfo, error) {
 	for rows.Next() {
 		var r ReviewInfo
 		if err := rows.Scan(&r.Title, &r.Difficulty, &r.LastCompletedAt, &r.NextReviewDate,
-			&r.Repetitions, &r.EasinessFactor, &r.DaysUntilReview); err != nil {
+			&r.Repetitions, &r.EasinessFactor, &r.DaysUntilReview, &r.Lapses); err != nil {
 			return nil, fmt.Errorf("scan review: %w", err)
 		}
 		reviews = append(reviews, r)
@@ -87,6 +125,7 @@ func formatReviewDate(dateStr sql.NullString) string {
 		"2006-01-02 15:04:05",
 		time.RFC3339,
 		"2006-01-02T15:04:05Z",
+		"2006-01-02", // date() values, e.g. buried_until
 	}
 
 	var t time.Time
@@ -105,19 +144,45 @@ func formatReviewDate(dateStr sql.NullString) string {
 	return t.Format("Jan 2, 2006")
 }
 
+// reviewDots are decoration only; the label says the same thing in words.
+var reviewDots = map[string]string{
+	"New":     "⚪",
+	"Overdue": "🔴",
+	"Today":   "🟠",
+	"Soon":    "🟡",
+	"Later":   "🟢",
+}
+
+// getReviewStatus returns a one-word label for a review (New, Overdue,
+// Today, Soon or Later) and a longer description.
 func getReviewStatus(days sql.NullInt64) (string, string) {
 	if !days.Valid {
-		return "⚪", "Never attempted"
+		return "New", "Never attempted"
 	}
 
 	d := days.Int64
 	if d < 0 {
-		return "🔴", fmt.Sprintf("Overdue by %d days", -d)
+		return "Overdue", fmt.Sprintf("Overdue by %d days", -d)
 	} else if d == 0 {
-		return "🟠", "Due today"
-	} else if d <= 3 {
-		return "🟡", fmt.Sprintf("Due in %d days", d)
+		return "Today", "Due today"
+	} else if d <= int64(settings.SoonDays) {
+		return "Soon", fmt.Sprintf("Due in %d days", d)
 	} else {
-		return "🟢", fmt.Sprintf("Due in %d days", d)
+		return "Later", fmt.Sprintf("Due in %d days", d)
 	}
 }
+
+// reviewRoles color status labels in tables.
+var reviewRoles = map[string]string{
+	"New":     roleAccent,
+	"Overdue": roleError,
+	"Today":   roleWarning,
+	"Soon":    roleWarning,
+	"Later":   roleSuccess,
+}
+
+// reviewIcon is the colored dot for a status label, or nothing in the ASCII
+// theme.
+func reviewIcon(label string) string {
+	return theme.icon(reviewDots[label])
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ==================== Search ====================
+
+const maxSearchResults = 15
+
+type SearchResult struct {
+	Problem Problem
+	Score   int
+	Field   string // Which field produced the best match
+}
+
+// searchProblems ranks every problem against query. Title matches outrank
+// topic, tag and company matches, which outrank notes; an exact LeetCode number wins
+// outright.
+func searchProblems(db *sql.DB, query string) ([]SearchResult, error) {
+	problems, err := getAllProblems(db)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := getAllProblemTags(db)
+	if err != nil {
+		return nil, err
+	}
+	companies, err := getAllProblemCompanies(db)
+	if err != nil {
+		return nil, err
+	}
+
+	num, numErr := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(query), "#"))
+	lowerQuery := strings.ToLower(query)
+
+	var results []SearchResult
+	for _, p := range problems {
+		best := SearchResult{Problem: p, Score: -1}
+		consider := func(score int, field string) {
+			if score > best.Score {
+				best.Score, best.Field = score, field
+			}
+		}
+
+		if numErr == nil && p.LeetcodeNumber == num {
+			consider(1000, "number")
+		}
+		consider(fuzzyScore(query, p.Title), "title")
+		if s := fuzzyScore(query, p.Grouping); s >= tierSubstring*100 {
+			consider(s-200, "topic")
+		}
+		for _, tag := range tags[p.ID] {
+			if s := fuzzyScore(query, tag); s >= tierSubstring*100 {
+				consider(s-200, "tag")
+			}
+		}
+		for _, c := range companies[p.ID] {
+			if s := fuzzyScore(query, c.Name); s >= tierSubstring*100 {
+				consider(s-200, "company")
+			}
+		}
+		if p.Notes != "" && strings.Contains(strings.ToLower(p.Notes), lowerQuery) {
+			consider(tierAllWords*100, "notes")
+		}
+
+		if best.Score >= 0 {
+			results = append(results, best)
+		}
+	}
+
+	slices.SortStableFunc(results, func(a, b SearchResult) int { return b.Score - a.Score })
+	return results, nil
+}
+
+func searchCommandWithDB(db *sql.DB, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: search <query>")
+	}
+	query := strings.Join(args, " ")
+
+	results, err := searchProblems(db, query)
+	if err != nil {
+		return err
+	}
+
+	if len(results) == 0 {
+		fmt.Printf("\nNo problems match %q\n", query)
+		return nil
+	}
+
+	if len(results) > maxSearchResults {
+		results = results[:maxSearchResults]
+	}
+
+	lastListing = lastListing[:0]
+	table := listingTable(fmt.Sprintf("\n%sResults for %q:", theme.icon("🔍"), query), "Topic", "Matched")
+	for i, r := range results {
+		p := r.Problem
+		table.AddRow(fmt.Sprintf("%d.", i+1), "["+p.Ref()+"]", hyperlink(problemURL(p), p.Title), p.Difficulty, p.Grouping, r.Field)
+		lastListing = append(lastListing, p)
+	}
+	table.Print()
+	fmt.Println()
+	if !interactive {
+		return nil
+	}
+
+	reader := stdin
+	for {
+		fmt.Print("Action? (d <n> = mark done, s <n> = show, o <n> = open, enter to finish): ")
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		fields := strings.Fields(input)
+		if len(fields) == 0 {
+			return nil
+		}
+		if len(fields) != 2 {
+			fmt.Println("Expected an action and a result number, e.g. 's 1'")
+			continue
+		}
+
+		num, err := strconv.Atoi(fields[1])
+		if err != nil || num < 1 || num > len(results) {
+			fmt.Printf("Invalid result number: %s\n", fields[1])
+			continue
+		}
+		problem := results[num-1].Problem
+
+		switch strings.ToLower(fields[0]) {
+		case "d", "done":
+			rating, err := readRating(reader, problem.Title)
+			if err != nil {
+				return err
+			}
+			if rating == 0 {
+				fmt.Println("Invalid rating, skipping...")
+				continue
+			}
+			if err := updateProblemCompletion(db, problem.Title, rating, 0, time.Now()); err != nil {
+				fmt.Printf("Error updating problem: %v\n", err)
+				continue
+			}
+			fmt.Println(success("Marked '%s' as completed with effort rating %d", problem.Title, rating))
+		case "s", "show":
+			if err := showCommandWithDB(db, []string{problem.Title}); err != nil {
+				fmt.Println(err)
+			}
+		case "o", "open":
+			if err := openProblem(db, problem); err != nil {
+				fmt.Println(err)
+			}
+		default:
+			fmt.Printf("Unknown action: %s\n", fields[0])
+		}
+	}
+}
@@ -3,8 +3,11 @@ package main
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 )
 
 // ==================== Seeding ====================
@@ -32,7 +35,9 @@ func seedNeetCodeFromJSON(db *sql.DB, jsonPath string) error {
 		return err
 	}
 
-	fmt.Printf("✓ Seeded %d NeetCode problems\n", len(problems))
+	if interactive {
+		fmt.Println(success("Seeded %d NeetCode problems", len(problems)))
+	}
 	return nil
 }
 
@@ -43,7 +48,10 @@ func insertProblems(db *sql.DB, problems []Problem) error {
 	}
 	defer tx.Rollback()
 
-	stmt, err := tx.Prepare("INSERT OR IGNORE INTO problems (title, difficulty, grouping, leetcode_number) VALUES (?, ?, ?, ?)")
+	stmt, err := tx.Prepare(`
+		INSERT OR IGNORE INTO problems (title, difficulty, grouping, leetcode_number, source, slug, url)
+		VALUES (?, ?, ?, ?, ?, NULLIF(?, ''), NULLIF(?, ''))
+	`)
 	if err != nil {
 		return fmt.Errorf("prepare statement: %w", err)
 	}
@@ -53,9 +61,62 @@ func insertProblems(db *sql.DB, problems []Problem) error {
 		if p.Title == "" {
 			continue
 		}
-		if _, err := stmt.Exec(p.Title, p.Difficulty, p.Grouping, p.LeetcodeNumber); err != nil {
+		source := p.Source
+		if source == "" {
+			source = sourceLeetCode
+		}
+		if _, err := stmt.Exec(p.Title, p.Difficulty, p.Grouping, p.LeetcodeNumber, source, p.Slug, p.URL); err != nil {
 			return fmt.Errorf("insert problem %q: %w", p.Title, err)
 		}
+
+		if len(p.Tags) == 0 && len(p.Companies) == 0 && len(p.Hints) == 0 && p.Complexity == nil {
+			continue
+		}
+
+		var id int
+		if err := tx.QueryRow("SELECT id FROM problems WHERE title = ?", p.Title).Scan(&id); err != nil {
+			return fmt.Errorf("find problem %q: %w", p.Title, err)
+		}
+		for _, tag := range p.Tags {
+			if err := addProblemTag(tx, id, tag); err != nil {
+				return err
+			}
+		}
+		for _, c := range p.Companies {
+			if err := upsertProblemCompany(tx, id, c); err != nil {
+				return err
+			}
+		}
+		for i, hint := range p.Hints {
+			if err := setHint(tx, id, i+1, hint); err != nil {
+				return fmt.Errorf("hints for %q: %w", p.Title, err)
+			}
+		}
+		if p.Complexity != nil {
+			if err := setComplexity(tx, id, *p.Complexity); err != nil {
+				return fmt.Errorf("complexity for %q: %w", p.Title, err)
+			}
+		}
+	}
+
+	// Link related problems once they all exist, so entries can refer forward
+	for _, p := range problems {
+		if len(p.Related) == 0 {
+			continue
+		}
+		id, err := seededProblemID(tx, p.Title)
+		if err != nil {
+			return err
+		}
+		for _, ref := range p.Related {
+			relatedID, err := seededProblemID(tx, ref)
+			if err != nil {
+				return fmt.Errorf("related problems of %q: %w", p.Title, err)
+			}
+			if err := linkProblems(tx, id, relatedID); err != nil {
+				return fmt.Errorf("related problems of %q: %w", p.Title, err)
+			}
+		}
 	}
 
 	if err := tx.Commit(); err != nil {
@@ -64,3 +125,23 @@ func insertProblems(db *sql.DB, problems []Problem) error {
 
 	return nil
 }
+
+// seededProblemID finds a problem by exact title or LeetCode number within
+// the seeding transaction.
+func seededProblemID(tx *sql.Tx, ref string) (int, error) {
+	var id int
+	number, _ := strconv.Atoi(strings.TrimSpace(ref))
+	err := tx.QueryRow(`
+		SELECT id FROM problems
+		WHERE LOWER(title) = LOWER(?) OR (? > 0 AND leetcode_number = ?)
+		ORDER BY LOWER(title) = LOWER(?) DESC
+		LIMIT 1
+	`, ref, number, number, ref).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("unknown problem %q", ref)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("find problem %q: %w", ref, err)
+	}
+	return id, nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ==================== Session ====================
+
+// errExit is returned by the exit command to end the REPL through the normal
+// shutdown path instead of calling os.Exit.
+var errExit = errors.New("exit")
+
+// Session tracks what was practiced since startup, for the summary printed on
+// the way out.
+type Session struct {
+	// writeMu is held while progress is being written, so a signal never
+	// closes the database halfway through a completion.
+	writeMu sync.Mutex
+
+	started   time.Time
+	completed []string
+	drilled   int
+	quizzed   int
+}
+
+var session = &Session{started: time.Now()}
+
+// record runs write while holding the write lock.
+func (s *Session) record(write func() error) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return write()
+}
+
+func (s *Session) printSummary() {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	elapsed := time.Since(s.started).Round(time.Minute)
+	if len(s.completed) == 0 && s.drilled == 0 && s.quizzed == 0 {
+		fmt.Printf("\nSession: %s, nothing practiced.\n", formatSessionDuration(elapsed))
+		return
+	}
+
+	fmt.Println("\n" + theme.icon("📋") + "Session Summary:")
+	fmt.Println("========================")
+	fmt.Printf("Time:           %s\n", formatSessionDuration(elapsed))
+	fmt.Printf("Problems done:  %d\n", len(s.completed))
+	for _, title := range s.completed {
+		fmt.Println("  " + success("%s", title))
+	}
+	if s.drilled > 0 {
+		fmt.Printf("Drill cards:    %d\n", s.drilled)
+	}
+	if s.quizzed > 0 {
+		fmt.Printf("Quiz cards:     %d\n", s.quizzed)
+	}
+}
+
+func formatSessionDuration(d time.Duration) string {
+	if d < time.Minute {
+		return "under a minute"
+	}
+	if d < time.Hour {
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
+
+var shutdownOnce sync.Once
+
+// shutdown closes the database, printing the session summary first in
+// interactive use. It is safe to call more than once; only the first call
+// does anything.
+func shutdown(db *sql.DB) {
+	shutdownOnce.Do(func() {
+		// Scripts get only the output of their commands
+		if interactive {
+			session.printSummary()
+		}
+
+		// Keep the lock so nothing starts writing to the closed database
+		session.writeMu.Lock()
+		if err := db.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to close database: %v\n", err)
+		}
+		if interactive {
+			fmt.Println(strings.TrimSpace("Thanks for using GoStudyNeetCode! Happy coding! " + theme.icon("👋")))
+		}
+	})
+}
+
+// handleSignals shuts down cleanly on SIGTERM, and on SIGINT when input isn't
+// interactive. At the REPL, SIGINT instead cancels the running command: any
+// prompt it is waiting at returns errInterrupted. At the prompt itself Ctrl-C
+// only clears the line, since the terminal is in raw mode.
+func handleSignals(db *sql.DB) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		sig := <-signals
+		for sig == syscall.SIGINT && interactive {
+			stdinSource.cancel()
+			sig = <-signals
+		}
+		restoreTerminal()
+		fmt.Println()
+		shutdown(db)
+
+		code := 130 // 128 + SIGINT
+		if sig == syscall.SIGTERM {
+			code = 143
+		}
+		os.Exit(code)
+	}()
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ==================== Problem Detail ====================
+
+var ratingLabels = map[int]string{1: "Easy", 2: "Medium", 3: "Hard"}
+
+type CompletionRecord struct {
+	CompletedAt     sql.NullString
+	NextReviewDate  sql.NullString
+	EffortRating    int
+	HintsUsed       int
+	IntervalDays    int
+	EasinessFactor  float64
+	Repetitions     int
+	DaysUntilReview sql.NullInt64
+}
+
+// getCompletionHistory returns every completion of a problem, oldest first.
+func getCompletionHistory(db *sql.DB, problemID int) ([]CompletionRecord, error) {
+	rows, err := db.Query(`
+		SELECT
+			completed_at,
+			next_review_date,
+			effort_rating,
+			hints_used,
+			interval_days,
+			easiness_factor,
+			repetitions,
+			CAST((julianday(date(next_review_date)) - julianday(date('now'))) AS INTEGER) as days_until
+		FROM completions
+		WHERE problem_id = ?
+		ORDER BY completed_at ASC, id ASC
+	`, problemID)
+	if err != nil {
+		return nil, fmt.Errorf("query completions: %w", err)
+	}
+	defer rows.Close()
+
+	var history []CompletionRecord
+	for rows.Next() {
+		var c CompletionRecord
+		if err := rows.Scan(&c.CompletedAt, &c.NextReviewDate, &c.EffortRating, &c.HintsUsed, &c.IntervalDays,
+			&c.EasinessFactor, &c.Repetitions, &c.DaysUntilReview); err != nil {
+			return nil, fmt.Errorf("scan completion: %w", err)
+		}
+		history = append(history, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate rows: %w", err)
+	}
+
+	return history, nil
+}
+
+func showCommandWithDB(db *sql.DB, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: show <title|LC number>")
+	}
+
+	problem, err := resolveProblem(db, strings.Join(args, " "))
+	if err != nil {
+		return err
+	}
+
+	history, err := getCompletionHistory(db, problem.ID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n%s%s\n", theme.icon("📘"), problem.Title)
+	fmt.Println("====================================================================================")
+	if problem.LeetcodeNumber > 0 {
+		fmt.Printf("  LeetCode:    #%d\n", problem.LeetcodeNumber)
+	}
+	if problem.Source != sourceLeetCode {
+		fmt.Printf("  Source:      %s\n", problem.Source)
+	}
+	if url := problemURL(problem); url != "" {
+		fmt.Printf("  Link:        %s\n", hyperlink(url, url))
+	}
+	fmt.Printf("  Difficulty:  %s\n", problem.Difficulty)
+	fmt.Printf("  Topic:       %s\n", problem.Grouping)
+
+	tags, err := getProblemTags(db, problem.ID)
+	if err != nil {
+		return err
+	}
+	if len(tags) > 0 {
+		fmt.Printf("  Tags:        %s\n", strings.Join(tags, ", "))
+	}
+
+	companies, err := getProblemCompanies(db, problem.ID)
+	if err != nil {
+		return err
+	}
+	if len(companies) > 0 {
+		var names []string
+		for _, c := range companies {
+			names = append(names, fmt.Sprintf("%s (%.0f)", c.Name, c.Frequency))
+		}
+		fmt.Printf("  Companies:   %s\n", strings.Join(names, ", "))
+	}
+
+	var days sql.NullInt64
+	if len(history) > 0 {
+		days = history[len(history)-1].DaysUntilReview
+	}
+	hints, err := getHints(db, problem.ID)
+	if err != nil {
+		return err
+	}
+	if len(hints) > 0 {
+		fmt.Printf("  Hints:       %d (reveal them in study with h, or 'hint show')\n", len(hints))
+	}
+
+	label, status := getReviewStatus(days)
+	fmt.Printf("  Status:      %s%s\n", reviewIcon(label), status)
+	fmt.Println()
+
+	statement, found, err := getStatement(db, problem.ID)
+	if err != nil {
+		return err
+	}
+	if found {
+		fmt.Println("Statement:")
+		printStatement(statement)
+		fmt.Println()
+	}
+
+	if problem.Notes != "" {
+		fmt.Println("Notes:")
+		for _, line := range strings.Split(problem.Notes, "\n") {
+			fmt.Printf("  %s\n", line)
+		}
+		fmt.Println()
+	}
+
+	fmt.Println("History:")
+	if len(history) == 0 {
+		fmt.Println("  Not attempted yet.")
+	} else {
+		table := Table{
+			Indent: "  ",
+			Columns: []Column{
+				{Header: "Completed"},
+				{Header: "Rating"},
+				{Header: "Hints", Right: true},
+				{Header: "Interval", Right: true},
+				{Header: "EF", Right: true},
+				{Header: "Next Review"},
+			},
+		}
+		for _, c := range history {
+			table.AddRow(
+				formatReviewDate(c.CompletedAt),
+				ratingLabels[c.EffortRating],
+				strconv.Itoa(c.HintsUsed),
+				fmt.Sprintf("%d days", c.IntervalDays),
+				fmt.Sprintf("%.2f", c.EasinessFactor),
+				formatReviewDate(c.NextReviewDate),
+			)
+		}
+		table.Print()
+	}
+	fmt.Println()
+
+	related, err := getRelatedProblems(db, problem, 5, false)
+	if err != nil {
+		return err
+	}
+	if len(related) > 0 {
+		fmt.Println("Related:")
+		for _, r := range related {
+			fmt.Printf("  - [%s] %s (%s)  [%s]\n", r.Ref(), r.Title, r.Difficulty, r.Reason())
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
@@ -3,20 +3,40 @@ package main
 import (
 	"database/sql"
 	"fmt"
+	"time"
 )
 
+// sqliteTimeLayout matches SQLite's CURRENT_TIMESTAMP so stored times compare as text.
+const sqliteTimeLayout = "2006-01-02 15:04:05"
+
 // ==================== Spaced Repetition (SM-2 Algorithm) ====================
 
-func updateProblemCompletion(db *sql.DB, title string, effortRating int) error {
+// updateProblemCompletion records a completion at completedAt, which may be in
+// the past when logging a solve after the fact.
+func updateProblemCompletion(db *sql.DB, title string, effortRating, hintsUsed int, completedAt time.Time) error {
 	problemID, err := getProblemID(db, title)
 	if err != nil {
 		return err
 	}
 
-	lastEF, lastInterval, lastReps := getLastCompletion(db, problemID)
-	newInterval, newEF, newReps := calculateSM2(effortRating, lastEF, lastInterval, lastReps)
+	lastEF, lastInterval, lastReps := getLastCompletion(db, problemID, completedAt)
+	newInterval, newEF, newReps := calculateSM2(effortRating, hintsUsed, lastEF, lastInterval, lastReps)
 
-	return insertCompletion(db, problemID, effortRating, newInterval, newEF, newReps)
+	// Make sure everything comes up again before the interview. Only the
+	// review date is capped: the stored interval stays the real SM-2 one, so
+	// the schedule picks up where it was after the interview.
+	reviewIn, err := capIntervalToTarget(db, newInterval, completedAt)
+	if err != nil {
+		return err
+	}
+
+	return session.record(func() error {
+		if err := insertCompletion(db, problemID, effortRating, hintsUsed, newInterval, reviewIn, newEF, newReps, completedAt); err != nil {
+			return err
+		}
+		session.completed = append(session.completed, title)
+		return nil
+	})
 }
 
 func getProblemID(db *sql.DB, title string) (int, error) {
@@ -28,27 +48,32 @@ func getProblemID(db *sql.DB, title string) (int, error) {
 	return problemID, nil
 }
 
-func getLastCompletion(db *sql.DB, problemID int) (ef float64, interval, reps int) {
-	ef, interval, reps = 2.5, 1, 0
+// getLastCompletion returns the SM-2 state as of the latest completion at or before `before`.
+func getLastCompletion(db *sql.DB, problemID int, before time.Time) (ef float64, interval, reps int) {
+	ef, interval, reps = settings.InitialEF, 1, 0
 	db.QueryRow(`
 		SELECT easiness_factor, interval_days, repetitions
 		FROM completions
-		WHERE problem_id = ?
+		WHERE problem_id = ? AND completed_at <= ?
 		ORDER BY completed_at DESC
 		LIMIT 1
-	`, problemID).Scan(&ef, &interval, &reps)
+	`, problemID, before.UTC().Format(sqliteTimeLayout)).Scan(&ef, &interval, &reps)
 	return
 }
 
-func calculateSM2(effortRating int, lastEF float64, lastInterval, lastReps int) (interval int, newEF float64, reps int) {
+func calculateSM2(effortRating, hintsUsed int, lastEF float64, lastInterval, lastReps int) (interval int, newEF float64, reps int) {
 	// effortRating: 1=Easy, 2=Medium, 3=Hard
 	// SM-2 quality scale: Easy=5, Medium=3, Hard=1
 	quality := map[int]int{1: 5, 2: 3, 3: 1}[effortRating]
 
+	// Each hint revealed costs one quality point, so a Medium solve that
+	// needed a hint counts as a lapse
+	quality = max(quality-hintsUsed, 0)
+
 	// Calculate new easiness factor
 	newEF = lastEF + (0.1 - float64(5-quality)*(0.08+float64(5-quality)*0.02))
-	if newEF < 1.3 {
-		newEF = 1.3
+	if newEF < settings.MinEF {
+		newEF = settings.MinEF
 	}
 
 	// Calculate interval and repetitions
@@ -61,16 +86,16 @@ func calculateSM2(effortRating int, lastEF float64, lastInterval, lastReps int)
 		case 1:
 			// First review: scale by quality
 			if quality == 5 { // Easy
-				interval = 4
-			} else { // Medium (quality == 3)
-				interval = 2
+				interval = settings.FirstIntervalEasy
+			} else { // Medium, or Easy with a hint
+				interval = settings.FirstIntervalMedium
 			}
 		case 2:
 			// Second review: scale by quality
 			if quality == 5 { // Easy
-				interval = 14
+				interval = settings.SecondIntervalEasy
 			} else { // Medium
-				interval = 7
+				interval = settings.SecondIntervalMedium
 			}
 		default:
 			interval = int(float64(lastInterval) * newEF)
@@ -80,10 +105,13 @@ func calculateSM2(effortRating int, lastEF float64, lastInterval, lastReps int)
 	return interval, newEF, reps
 }
 
-func insertCompletion(db *sql.DB, problemID, effortRating, interval int, ef float64, reps int) error {
+// insertCompletion stores a completion's SM-2 state, with the next review
+// reviewIn days later (the interval, unless capped for the target date).
+func insertCompletion(db *sql.DB, problemID, effortRating, hintsUsed, interval, reviewIn int, ef float64, reps int, completedAt time.Time) error {
+	at := completedAt.UTC().Format(sqliteTimeLayout)
 	_, err := db.Exec(`
-		INSERT INTO completions (problem_id, effort_rating, interval_days, easiness_factor, repetitions, next_review_date)
-		VALUES (?, ?, ?, ?, ?, datetime('now', '+' || ? || ' days'))
-	`, problemID, effortRating, interval, ef, reps, interval)
+		INSERT INTO completions (problem_id, effort_rating, hints_used, interval_days, easiness_factor, repetitions, completed_at, next_review_date)
+		VALUES (?, ?, ?, ?, ?, ?, ?, datetime(?, '+' || ? || ' days'))
+	`, problemID, effortRating, hintsUsed, interval, ef, reps, at, at, reviewIn)
 	return err
 }
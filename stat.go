@@ -2,8 +2,9 @@ package main
 
 import (
 	"database/sql"
+	"flag"
 	"fmt"
-	"time"
+	"strconv"
 )
 
 // ==================== Stats ====================
@@ -14,6 +15,11 @@ type OverallStats struct {
 	CompletedProblems int
 	RemainingProblems int
 
+	// Out of rotation (not counted above)
+	SuspendedProblems int
+	RetiredProblems   int
+	BuriedProblems    int // Active, but hidden until tomorrow
+
 	// By difficulty
 	EasyTotal       int
 	EasyCompleted   int
@@ -26,25 +32,51 @@ type OverallStats struct {
 	ProblemsNeedReview int
 	OverdueReviews     int
 	DueTodayReviews    int
-	UpcomingReviews    int // Due within 3 days
+	UpcomingReviews    int // Due within settings.SoonDays
+
+	CompletionProjection
+}
 
-	// Projection stats
-	EstimatedDaysToComplete int // At 3 problems/day
-	EstimatedCompletionDate string
+// CompletionProjection is the part of the stats simulated from recent
+// history, kept apart since it is much slower to work out than the counts.
+type CompletionProjection struct {
+	HistoryDays     int
+	Throughput      float64 // Problems per day used by the simulation
+	Mix             RatingMix
+	FromHistory     bool // False when falling back to the default pace
+	P50Days         int  // -1 when not reached within maxProjectionDays
+	P90Days         int
+	P50CompletionAt string
+	P90CompletionAt string
 }
 
-func getOverallStats(db *sql.DB) (*OverallStats, error) {
+func getOverallStats(db *sql.DB, historyDays int, filter ProblemFilter) (*OverallStats, error) {
+	stats, err := getStatCounts(db, filter)
+	if err != nil {
+		return nil, err
+	}
+	stats.HistoryDays = historyDays
+	if err := projectCompletion(db, stats, filter); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// getStatCounts is getOverallStats without the projection.
+func getStatCounts(db *sql.DB, filter ProblemFilter) (*OverallStats, error) {
 	stats := &OverallStats{}
+	clause, args := filter.where()
 
 	// Get total problems by difficulty
 	diffQuery := `
 		SELECT
-			LOWER(difficulty) as diff,
+			LOWER(p.difficulty) as diff,
 			COUNT(*) as total
-		FROM problems
-		GROUP BY LOWER(difficulty)
+		FROM problems p
+		WHERE p.state = 'active'` + clause + `
+		GROUP BY LOWER(p.difficulty)
 	`
-	rows, err := db.Query(diffQuery)
+	rows, err := db.Query(diffQuery, args...)
 	if err != nil {
 		return nil, fmt.Errorf("query difficulty totals: %w", err)
 	}
@@ -74,9 +106,10 @@ func getOverallStats(db *sql.DB) (*OverallStats, error) {
 			COUNT(DISTINCT p.id) as completed
 		FROM problems p
 		INNER JOIN completions c ON p.id = c.problem_id
+		WHERE p.state = 'active'` + clause + `
 		GROUP BY LOWER(p.difficulty)
 	`
-	rows, err = db.Query(completedQuery)
+	rows, err = db.Query(completedQuery, args...)
 	if err != nil {
 		return nil, fmt.Errorf("query completed problems: %w", err)
 	}
@@ -101,13 +134,26 @@ func getOverallStats(db *sql.DB) (*OverallStats, error) {
 
 	stats.RemainingProblems = stats.TotalProblems - stats.CompletedProblems
 
+	// Get suspended, retired and buried counts
+	stateQuery := `
+		SELECT
+			COALESCE(SUM(p.state = 'suspended'), 0),
+			COALESCE(SUM(p.state = 'retired'), 0),
+			COALESCE(SUM(p.state = 'active' AND date(p.buried_until) > date('now')), 0)
+		FROM problems p
+		WHERE 1 = 1` + clause + `
+	`
+	if err := db.QueryRow(stateQuery, args...).Scan(&stats.SuspendedProblems, &stats.RetiredProblems, &stats.BuriedProblems); err != nil {
+		return nil, fmt.Errorf("query problem states: %w", err)
+	}
+
 	// Get review status counts
 	reviewQuery := `
 		SELECT
 			CASE
 				WHEN julianday(date(c.next_review_date)) - julianday(date('now')) < 0 THEN 'overdue'
 				WHEN julianday(date(c.next_review_date)) - julianday(date('now')) = 0 THEN 'today'
-				WHEN julianday(date(c.next_review_date)) - julianday(date('now')) <= 3 THEN 'upcoming'
+				WHEN julianday(date(c.next_review_date)) - julianday(date('now')) <= ? THEN 'upcoming'
 				ELSE 'future'
 			END as status,
 			COUNT(*) as count
@@ -117,10 +163,10 @@ func getOverallStats(db *sql.DB) (*OverallStats, error) {
 			FROM completions
 			GROUP BY problem_id
 		) c ON p.id = c.problem_id
-		WHERE c.next_review_date IS NOT NULL
+		WHERE c.next_review_date IS NOT NULL AND p.state = 'active'` + clause + `
 		GROUP BY status
 	`
-	rows, err = db.Query(reviewQuery)
+	rows, err = db.Query(reviewQuery, append([]any{settings.SoonDays}, args...)...)
 	if err != nil {
 		return nil, fmt.Errorf("query review status: %w", err)
 	}
@@ -144,36 +190,49 @@ func getOverallStats(db *sql.DB) (*OverallStats, error) {
 
 	stats.ProblemsNeedReview = stats.OverdueReviews + stats.DueTodayReviews + stats.UpcomingReviews
 
-	// Calculate estimated days to complete
-	// Assumption: 3 problems per day total (including both new problems and reviews)
-	// Each new problem marked as "easy" generates 2 reviews (at day 4 and day 14)
-	// Total work = remaining problems + (2 reviews per new problem) + current review backlog
-	const reviewsPerProblem = 2.0
-
-	totalWork := float64(stats.RemainingProblems)*(1.0+reviewsPerProblem) + float64(stats.ProblemsNeedReview)
-	stats.EstimatedDaysToComplete = int((totalWork + 2.0) / 3.0) // Round up
+	return stats, nil
+}
 
-	// Calculate estimated completion date
-	estimatedDate := time.Now().AddDate(0, 0, stats.EstimatedDaysToComplete)
-	stats.EstimatedCompletionDate = estimatedDate.Format("Jan 2, 2006")
+type statOptions struct {
+	Days int
+	Tag  string
+}
 
-	return stats, nil
+func statFlags(o *statOptions) *flag.FlagSet {
+	fs := newFlagSet("stat")
+	fs.IntVar(&o.Days, "days", historyWindowDays, "Days of history used for the projection")
+	fs.StringVar(&o.Tag, "tag", "", "Only count problems with this tag")
+	fs.StringVar(&o.Tag, "t", "", "Short for tag")
+	return fs
 }
 
 func statCommandWithDB(db *sql.DB, args []string) error {
-	stats, err := getOverallStats(db)
+	var opts statOptions
+	err := statFlags(&opts).Parse(args)
+	if err != nil {
+		return err
+	}
+	if opts.Days < 1 {
+		return fmt.Errorf("days must be at least 1")
+	}
+
+	stats, err := getOverallStats(db, opts.Days, ProblemFilter{Tag: opts.Tag})
 	if err != nil {
 		return fmt.Errorf("get stats: %w", err)
 	}
 
 	fmt.Println()
-	fmt.Println("📊 Your Study Statistics")
-	fmt.Println("═══════════════════════════════════════════════════════════")
+	if opts.Tag != "" {
+		fmt.Printf("%sYour Study Statistics (tag: %s)\n", theme.icon("📊"), normalizeTag(opts.Tag))
+	} else {
+		fmt.Println(theme.icon("📊") + "Your Study Statistics")
+	}
+	fmt.Println(theme.rule("═", 59))
 	fmt.Println()
 
 	// Overall Progress
 	fmt.Println("Overall Progress:")
-	fmt.Println("─────────────────────────────────────────────────────────")
+	fmt.Println(theme.rule("─", 57))
 	totalPercent := 0.0
 	if stats.TotalProblems > 0 {
 		totalPercent = float64(stats.CompletedProblems) / float64(stats.TotalProblems) * 100
@@ -181,80 +240,94 @@ func statCommandWithDB(db *sql.DB, args []string) error {
 	fmt.Printf("  Total:      %d / %d problems completed (%.1f%%)\n",
 		stats.CompletedProblems, stats.TotalProblems, totalPercent)
 	fmt.Printf("  Remaining:  %d problems\n", stats.RemainingProblems)
+	if stats.SuspendedProblems+stats.RetiredProblems+stats.BuriedProblems > 0 {
+		fmt.Printf("  Set aside:  %d suspended, %d retired, %d buried until tomorrow\n",
+			stats.SuspendedProblems, stats.RetiredProblems, stats.BuriedProblems)
+	}
 	fmt.Println()
 
 	// Progress by Difficulty
 	fmt.Println("Progress by Difficulty:")
-	fmt.Println("─────────────────────────────────────────────────────────")
+	fmt.Println(theme.rule("─", 57))
 
-	// Easy
-	easyPercent := 0.0
-	if stats.EasyTotal > 0 {
-		easyPercent = float64(stats.EasyCompleted) / float64(stats.EasyTotal) * 100
+	difficulties := Table{
+		Indent:  "  ",
+		Columns: []Column{{}, {Right: true}, {}, {Right: true}, {}},
 	}
-	fmt.Printf("  \033[32mEasy:\033[0m     %3d / %-3d (%5.1f%%)  %s\n",
-		stats.EasyCompleted, stats.EasyTotal, easyPercent, progressBar(easyPercent, "green"))
-
-	// Medium
-	mediumPercent := 0.0
-	if stats.MediumTotal > 0 {
-		mediumPercent = float64(stats.MediumCompleted) / float64(stats.MediumTotal) * 100
-	}
-	fmt.Printf("  \033[33mMedium:\033[0m   %3d / %-3d (%5.1f%%)  %s\n",
-		stats.MediumCompleted, stats.MediumTotal, mediumPercent, progressBar(mediumPercent, "yellow"))
-
-	// Hard
-	hardPercent := 0.0
-	if stats.HardTotal > 0 {
-		hardPercent = float64(stats.HardCompleted) / float64(stats.HardTotal) * 100
+	for _, d := range []struct {
+		label            string
+		completed, total int
+	}{
+		{"Easy", stats.EasyCompleted, stats.EasyTotal},
+		{"Medium", stats.MediumCompleted, stats.MediumTotal},
+		{"Hard", stats.HardCompleted, stats.HardTotal},
+	} {
+		percent := 0.0
+		if d.total > 0 {
+			percent = float64(d.completed) / float64(d.total) * 100
+		}
+		role := difficultyRole(d.label)
+		difficulties.AddRow(theme.paint(role, d.label+":"), strconv.Itoa(d.completed), "/ "+strconv.Itoa(d.total),
+			fmt.Sprintf("(%.1f%%)", percent), progressBar(percent, role))
 	}
-	fmt.Printf("  \033[31mHard:\033[0m     %3d / %-3d (%5.1f%%)  %s\n",
-		stats.HardCompleted, stats.HardTotal, hardPercent, progressBar(hardPercent, "red"))
+	difficulties.Print()
 	fmt.Println()
 
 	// Review Status
 	fmt.Println("Review Status:")
-	fmt.Println("─────────────────────────────────────────────────────────")
-	fmt.Printf("  🔴 Overdue:  %d problems\n", stats.OverdueReviews)
-	fmt.Printf("  🟠 Today:    %d problems\n", stats.DueTodayReviews)
-	fmt.Printf("  🟡 Soon:     %d problems (within 3 days)\n", stats.UpcomingReviews)
+	fmt.Println(theme.rule("─", 57))
+	reviewStatus := Table{
+		Indent:  "  ",
+		Columns: []Column{{}, {Right: true}, {}},
+	}
+	reviewStatus.AddRow(reviewIcon("Overdue")+"Overdue:", strconv.Itoa(stats.OverdueReviews), "problems")
+	reviewStatus.AddRow(reviewIcon("Today")+"Today:", strconv.Itoa(stats.DueTodayReviews), "problems")
+	reviewStatus.AddRow(reviewIcon("Soon")+"Soon:", strconv.Itoa(stats.UpcomingReviews), fmt.Sprintf("problems (within %d days)", settings.SoonDays))
+	reviewStatus.Print()
 	fmt.Println()
 
 	// Projections
-	fmt.Println("Projections (at 3 problems/day assuming \033[32mEasy\033[0m completions):")
-	fmt.Println("─────────────────────────────────────────────────────────")
-	fmt.Printf("  Estimated days to complete:  %d days\n", stats.EstimatedDaysToComplete)
-	fmt.Printf("  Estimated completion date:   %s\n", stats.EstimatedCompletionDate)
+	if stats.FromHistory {
+		fmt.Printf("Projections (last %d days: %.1f problems/day, %.0f%% Easy / %.0f%% Medium / %.0f%% Hard):\n",
+			stats.HistoryDays, stats.Throughput, stats.Mix.Easy*100, stats.Mix.Medium*100, stats.Mix.Hard*100)
+	} else {
+		fmt.Printf("Projections (no recent history; assuming %d problems/day with %s completions):\n",
+			settings.ProblemsPerDay, theme.paint(roleEasy, "Easy"))
+	}
+	fmt.Println(theme.rule("─", 57))
+	fmt.Printf("  50%% chance done by:  %s\n", formatProjection(stats.P50Days, stats.P50CompletionAt))
+	fmt.Printf("  90%% chance done by:  %s\n", formatProjection(stats.P90Days, stats.P90CompletionAt))
 	fmt.Println()
 
+	// Interview deadline
+	target, ok, err := getTargetDate(db)
+	if err != nil {
+		return err
+	}
+	if ok {
+		plan, err := buildDeadlinePlan(db, target)
+		if err != nil {
+			return fmt.Errorf("build plan: %w", err)
+		}
+		fmt.Println("Interview Deadline:")
+		fmt.Println(theme.rule("─", 57))
+		printDeadlinePlan(plan)
+	}
+
 	return nil
 }
 
-func progressBar(percent float64, color string) string {
+// progressBar draws a 20-cell bar, filled in the role's color.
+func progressBar(percent float64, role string) string {
 	barLength := 20
 	filled := min(int(percent/100.0*float64(barLength)), barLength)
 
-	// ANSI color codes
-	colorCodes := map[string]string{
-		"green":  "\033[32m",
-		"yellow": "\033[33m",
-		"red":    "\033[31m",
-		"cyan":   "\033[36m",
-		"reset":  "\033[0m",
-	}
-
-	colorCode := colorCodes[color]
-	if colorCode == "" {
-		colorCode = colorCodes["cyan"]
-	}
-	resetCode := colorCodes["reset"]
-
 	bar := "["
 	for i := range barLength {
 		if i < filled {
-			bar += colorCode + "█" + resetCode
+			bar += theme.paint(role, theme.symbol("█"))
 		} else {
-			bar += "░"
+			bar += theme.symbol("░")
 		}
 	}
 	bar += "]"
@@ -0,0 +1,422 @@
+package main
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ==================== Problem Statements ====================
+
+const statementWidth = 80
+
+type Statement struct {
+	Body        string
+	Examples    []string
+	Constraints []string
+}
+
+// statementEntry is one problem in a JSON bundle. The problem is identified
+// by "problem" (title or LeetCode number), or by "title"/"leetcode_number".
+type statementEntry struct {
+	Problem        string   `json:"problem"`
+	Title          string   `json:"title"`
+	LeetcodeNumber int      `json:"leetcode_number"`
+	Statement      string   `json:"statement"`
+	Examples       []string `json:"examples"`
+	Constraints    []string `json:"constraints"`
+}
+
+func (e statementEntry) key() string {
+	switch {
+	case e.Problem != "":
+		return e.Problem
+	case e.Title != "":
+		return e.Title
+	case e.LeetcodeNumber > 0:
+		return strconv.Itoa(e.LeetcodeNumber)
+	}
+	return ""
+}
+
+func saveStatement(db execer, problemID int, s Statement) error {
+	examples, err := json.Marshal(nonNil(s.Examples))
+	if err != nil {
+		return err
+	}
+	constraints, err := json.Marshal(nonNil(s.Constraints))
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO statements (problem_id, body, examples, constraints, updated_at)
+		VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(problem_id) DO UPDATE SET
+			body = excluded.body,
+			examples = excluded.examples,
+			constraints = excluded.constraints,
+			updated_at = excluded.updated_at
+	`, problemID, strings.TrimSpace(s.Body), string(examples), string(constraints))
+	if err != nil {
+		return fmt.Errorf("save statement: %w", err)
+	}
+	return nil
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+// getStatement returns the cached statement for a problem, if any.
+func getStatement(db *sql.DB, problemID int) (Statement, bool, error) {
+	var s Statement
+	var examples, constraints string
+	err := db.QueryRow("SELECT body, examples, constraints FROM statements WHERE problem_id = ?", problemID).
+		Scan(&s.Body, &examples, &constraints)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Statement{}, false, nil
+	}
+	if err != nil {
+		return Statement{}, false, fmt.Errorf("query statement: %w", err)
+	}
+	if err := json.Unmarshal([]byte(examples), &s.Examples); err != nil {
+		return Statement{}, false, fmt.Errorf("decode examples: %w", err)
+	}
+	if err := json.Unmarshal([]byte(constraints), &s.Constraints); err != nil {
+		return Statement{}, false, fmt.Errorf("decode constraints: %w", err)
+	}
+	return s, true, nil
+}
+
+func deleteStatement(db *sql.DB, problemID int) (bool, error) {
+	res, err := db.Exec("DELETE FROM statements WHERE problem_id = ?", problemID)
+	if err != nil {
+		return false, fmt.Errorf("delete statement: %w", err)
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// parseStatementJSON reads a bundle: a JSON array of statementEntry.
+func parseStatementJSON(r io.Reader) ([]statementEntry, error) {
+	var entries []statementEntry
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("decode JSON: %w", err)
+	}
+	return entries, nil
+}
+
+// parseStatementMarkdown reads a bundle where each "# <title or LC number>"
+// heading starts a problem. Within a problem, "## Example..." headings start
+// an example and "## Constraints" starts a bullet list of constraints;
+// everything else is the statement body.
+func parseStatementMarkdown(r io.Reader) ([]statementEntry, error) {
+	var entries []statementEntry
+	var body, example []string
+	section := "body"
+	inFence := false
+
+	flushExample := func() {
+		if text := strings.TrimSpace(strings.Join(example, "\n")); text != "" {
+			entries[len(entries)-1].Examples = append(entries[len(entries)-1].Examples, text)
+		}
+		example = nil
+	}
+	flush := func() {
+		if len(entries) == 0 {
+			return
+		}
+		flushExample()
+		entries[len(entries)-1].Statement = strings.TrimSpace(strings.Join(body, "\n"))
+		body = nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inFence = !inFence
+		}
+
+		switch {
+		case !inFence && strings.HasPrefix(line, "# "):
+			flush()
+			entries = append(entries, statementEntry{Problem: strings.TrimSpace(line[2:])})
+			section = "body"
+			continue
+		case len(entries) == 0:
+			// Anything before the first problem heading is a preamble
+			continue
+		case !inFence && strings.HasPrefix(line, "## "):
+			heading := strings.ToLower(strings.TrimSpace(line[3:]))
+			switch {
+			case strings.HasPrefix(heading, "example"):
+				flushExample()
+				section = "example"
+				continue
+			case strings.HasPrefix(heading, "constraint"):
+				flushExample()
+				section = "constraints"
+				continue
+			}
+		}
+
+		switch section {
+		case "example":
+			example = append(example, line)
+		case "constraints":
+			if c := strings.TrimSpace(strings.TrimLeft(trimmed, "-*")); c != "" {
+				entries[len(entries)-1].Constraints = append(entries[len(entries)-1].Constraints, c)
+			}
+		default:
+			body = append(body, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read markdown: %w", err)
+	}
+	flush()
+	return entries, nil
+}
+
+// readStatementBundle parses a .json or .md bundle, or every such file in a
+// directory.
+func readStatementBundle(path string) ([]statementEntry, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		files, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		var entries []statementEntry
+		for _, f := range files {
+			if f.IsDir() || !isStatementFile(f.Name()) {
+				continue
+			}
+			more, err := readStatementBundle(filepath.Join(path, f.Name()))
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, more...)
+		}
+		return entries, nil
+	}
+
+	if !isStatementFile(path) {
+		return nil, fmt.Errorf("%s: expected a .json, .md or .markdown file", path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []statementEntry
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		entries, err = parseStatementJSON(f)
+	} else {
+		entries, err = parseStatementMarkdown(f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return entries, nil
+}
+
+func isStatementFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json", ".md", ".markdown":
+		return true
+	}
+	return false
+}
+
+// importStatements saves every entry that matches a problem by exact title or
+// LeetCode number. Entries that don't match are returned as skipped.
+func importStatements(db *sql.DB, entries []statementEntry) (imported int, skipped []string, err error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for i, e := range entries {
+		key := e.key()
+		if key == "" {
+			return 0, nil, fmt.Errorf("entry %d: no problem title or number", i+1)
+		}
+		if strings.TrimSpace(e.Statement) == "" {
+			return 0, nil, fmt.Errorf("%s: statement is empty", key)
+		}
+
+		problem, found, err := lookupProblemExact(tx, key)
+		if err != nil {
+			return 0, nil, err
+		}
+		if !found {
+			skipped = append(skipped, key)
+			continue
+		}
+
+		s := Statement{Body: e.Statement, Examples: e.Examples, Constraints: e.Constraints}
+		if err := saveStatement(tx, problem.ID, s); err != nil {
+			return 0, nil, fmt.Errorf("%s: %w", key, err)
+		}
+		imported++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, nil, fmt.Errorf("commit transaction: %w", err)
+	}
+	return imported, skipped, nil
+}
+
+// wrapText word-wraps each line of text to width, prefixing every output
+// line with indent. Indented lines (code, ASCII diagrams) are left alone.
+func wrapText(text, indent string, width int) []string {
+	var out []string
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			out = append(out, "")
+			continue
+		}
+		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") || len(indent)+len(line) <= width {
+			out = append(out, indent+line)
+			continue
+		}
+
+		current := indent
+		for _, word := range strings.Fields(line) {
+			if current != indent && len(current)+1+len(word) > width {
+				out = append(out, current)
+				current = indent
+			}
+			if current != indent {
+				current += " "
+			}
+			current += word
+		}
+		out = append(out, current)
+	}
+	return out
+}
+
+func printStatement(s Statement) {
+	for _, line := range wrapText(s.Body, "  ", statementWidth) {
+		fmt.Println(line)
+	}
+
+	for i, ex := range s.Examples {
+		fmt.Printf("\n  Example %d:\n", i+1)
+		for _, line := range strings.Split(ex, "\n") {
+			fmt.Printf("    %s\n", line)
+		}
+	}
+
+	if len(s.Constraints) > 0 {
+		fmt.Println("\n  Constraints:")
+		for _, c := range s.Constraints {
+			fmt.Printf("    • %s\n", c)
+		}
+	}
+}
+
+// showStatement prints a problem's cached statement under its title.
+func showStatement(db *sql.DB, p Problem) error {
+	s, found, err := getStatement(db, p.ID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		fmt.Printf("No statement cached for '%s'; import one with 'statement import <file>'.\n", p.Title)
+		return nil
+	}
+
+	fmt.Printf("\n%s[%s] %s (%s)\n", theme.icon("📄"), p.Ref(), p.Title, p.Difficulty)
+	fmt.Println("====================================================================================")
+	printStatement(s)
+	fmt.Println()
+	return nil
+}
+
+func statementFlags(remove *bool) *flag.FlagSet {
+	fs := newFlagSet("statement")
+	fs.BoolVar(remove, "remove", false, "Delete the problem's cached statement instead of showing it")
+	return fs
+}
+
+func statementCommandWithDB(db *sql.DB, args []string) error {
+	usage := fmt.Errorf("usage: statement <title|LC number>, statement import <file.json|file.md|dir>, or statement --remove <problem>")
+	if len(args) == 0 {
+		return usage
+	}
+
+	// A title that happens to start with "import" is still a title
+	if problem, found, err := lookupProblemExact(db, strings.Join(args, " ")); err != nil {
+		return err
+	} else if found {
+		return showStatement(db, problem)
+	}
+
+	if args[0] == "import" {
+		if len(args) != 2 {
+			return usage
+		}
+		entries, err := readStatementBundle(args[1])
+		if err != nil {
+			return fmt.Errorf("read %s: %w", args[1], err)
+		}
+		imported, skipped, err := importStatements(db, entries)
+		if err != nil {
+			return fmt.Errorf("import %s: %w", args[1], err)
+		}
+		fmt.Println(success("Imported %d statements", imported))
+		if len(skipped) > 0 {
+			fmt.Printf("%sSkipped %d entries with unknown problems: %s\n", theme.icon("ℹ"), len(skipped), strings.Join(skipped, ", "))
+		}
+		return nil
+	}
+
+	var remove bool
+	positional, err := parseInterspersed(statementFlags(&remove), args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		return usage
+	}
+	problem, err := resolveProblem(db, strings.Join(positional, " "))
+	if err != nil {
+		return err
+	}
+
+	if !remove {
+		return showStatement(db, problem)
+	}
+	removed, err := deleteStatement(db, problem.ID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return fmt.Errorf("'%s' has no cached statement", problem.Title)
+	}
+	fmt.Println(success("Removed the statement for '%s'", problem.Title))
+	return nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// ==================== Suspend / Bury / Retire ====================
+
+type SetAsideProblem struct {
+	Problem     Problem
+	State       string
+	BuriedUntil sql.NullString
+}
+
+func setProblemState(db *sql.DB, problemID int, state string) error {
+	_, err := db.Exec("UPDATE problems SET state = ?, buried_until = NULL WHERE id = ?", state, problemID)
+	if err != nil {
+		return fmt.Errorf("update problem state: %w", err)
+	}
+	return nil
+}
+
+// buryProblem hides a problem from study until the start of tomorrow.
+func buryProblem(db *sql.DB, problemID int) error {
+	_, err := db.Exec("UPDATE problems SET buried_until = date('now', '+1 day') WHERE id = ?", problemID)
+	if err != nil {
+		return fmt.Errorf("bury problem: %w", err)
+	}
+	return nil
+}
+
+// getSetAsideProblems lists suspended, retired and currently buried problems.
+func getSetAsideProblems(db *sql.DB) ([]SetAsideProblem, error) {
+	rows, err := db.Query("SELECT "+problemColumns+`, p.state, p.buried_until
+		FROM problems p
+		WHERE p.state != ? OR date(p.buried_until) > date('now')
+		ORDER BY p.state, p.title
+	`, stateActive)
+	if err != nil {
+		return nil, fmt.Errorf("query set-aside problems: %w", err)
+	}
+	defer rows.Close()
+
+	var problems []SetAsideProblem
+	for rows.Next() {
+		var s SetAsideProblem
+		var err error
+		s.Problem, err = scanProblem(rows, &s.State, &s.BuriedUntil)
+		if err != nil {
+			return nil, fmt.Errorf("scan problem: %w", err)
+		}
+		problems = append(problems, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate rows: %w", err)
+	}
+
+	return problems, nil
+}
+
+// setAsideCommand builds the suspend/retire/bury/unsuspend commands, which
+// differ only in how they update the resolved problem.
+func setAsideCommand(db *sql.DB, name, done string, apply func(db *sql.DB, id int) error) func(args []string) error {
+	return func(args []string) error {
+		if len(args) == 0 {
+			return fmt.Errorf("usage: %s <title|LC number>", name)
+		}
+
+		problem, err := resolveProblem(db, strings.Join(args, " "))
+		if err != nil {
+			return err
+		}
+
+		if err := apply(db, problem.ID); err != nil {
+			return err
+		}
+
+		fmt.Println(success("'%s' %s", problem.Title, done))
+		return nil
+	}
+}
+
+func suspendedCommandWithDB(db *sql.DB, args []string) error {
+	problems, err := getSetAsideProblems(db)
+	if err != nil {
+		return err
+	}
+
+	if len(problems) == 0 {
+		fmt.Println("\nNo suspended, retired or buried problems.")
+		return nil
+	}
+
+	table := Table{
+		Title: "\n" + theme.icon("⏸") + "Out of Rotation:",
+		Columns: []Column{
+			{Header: "State"},
+			{Header: "Problem", Flex: true, Min: 12},
+			{Header: "Difficulty"},
+			{Header: "Until"},
+		},
+	}
+	for _, s := range problems {
+		state, until := s.State, "-"
+		if state == stateActive {
+			state, until = "buried", formatReviewDate(s.BuriedUntil)
+		}
+		table.AddRow(state, s.Problem.Title, s.Problem.Difficulty, until)
+	}
+	table.Print()
+	fmt.Println()
+	fmt.Println("Use 'unsuspend <problem>' to bring a problem back into rotation.")
+	fmt.Println()
+
+	return nil
+}
@@ -0,0 +1,354 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// ==================== Tables ====================
+
+// wideRanges are the code points terminals draw two columns wide: East Asian
+// wide and fullwidth characters, and emoji.
+var wideRanges = [][2]rune{
+	{0x1100, 0x115F}, {0x231A, 0x231B}, {0x2329, 0x232A}, {0x23E9, 0x23EC},
+	{0x23F0, 0x23F0}, {0x23F3, 0x23F3}, {0x25FD, 0x25FE}, {0x2614, 0x2615},
+	{0x2648, 0x2653}, {0x267F, 0x267F}, {0x2693, 0x2693}, {0x26A1, 0x26A1},
+	{0x26AA, 0x26AB}, {0x26BD, 0x26BE}, {0x26C4, 0x26C5}, {0x26CE, 0x26CE},
+	{0x26D4, 0x26D4}, {0x26EA, 0x26EA}, {0x26F2, 0x26F3}, {0x26F5, 0x26F5},
+	{0x26FA, 0x26FA}, {0x26FD, 0x26FD}, {0x2705, 0x2705}, {0x270A, 0x270B},
+	{0x2728, 0x2728}, {0x274C, 0x274C}, {0x274E, 0x274E}, {0x2753, 0x2755},
+	{0x2757, 0x2757}, {0x2795, 0x2797}, {0x27B0, 0x27B0}, {0x27BF, 0x27BF},
+	{0x2B1B, 0x2B1C}, {0x2B50, 0x2B50}, {0x2B55, 0x2B55}, {0x2E80, 0x303E},
+	{0x3041, 0x33FF}, {0x3400, 0x4DBF}, {0x4E00, 0x9FFF}, {0xA000, 0xA4CF},
+	{0xAC00, 0xD7A3}, {0xF900, 0xFAFF}, {0xFE30, 0xFE4F}, {0xFF00, 0xFF60},
+	{0xFFE0, 0xFFE6}, {0x1F300, 0x1F64F}, {0x1F680, 0x1F6FF}, {0x1F7E0, 0x1F7EB}, {0x1F900, 0x1F9FF},
+	{0x1FA70, 0x1FAFF}, {0x20000, 0x3FFFD},
+}
+
+// runeWidth is the number of terminal columns r takes up.
+func runeWidth(r rune) int {
+	switch {
+	case r == 0x200D || (r >= 0xFE00 && r <= 0xFE0F): // Joiners and variation selectors
+		return 0
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf, unicode.Cc):
+		return 0
+	}
+	for _, wide := range wideRanges {
+		if r < wide[0] {
+			break
+		}
+		if r <= wide[1] {
+			return 2
+		}
+	}
+	return 1
+}
+
+// escapeLen returns the length of the ANSI escape sequence at the start of s,
+// or 0. Both color codes (CSI) and hyperlinks (OSC, ended by ST or BEL) are
+// recognized, since table cells can contain either.
+func escapeLen(s string) int {
+	if len(s) < 2 || s[0] != '\033' {
+		return 0
+	}
+	switch s[1] {
+	case '[':
+		for i := 2; i < len(s); i++ {
+			if s[i] >= 0x40 && s[i] <= 0x7E {
+				return i + 1
+			}
+		}
+	case ']':
+		for i := 2; i < len(s); i++ {
+			if s[i] == '\a' {
+				return i + 1
+			}
+			if s[i] == '\033' && i+1 < len(s) && s[i+1] == '\\' {
+				return i + 2
+			}
+		}
+	}
+	return len(s)
+}
+
+// displayWidth is how many columns s takes up in a terminal, not counting
+// escape sequences.
+func displayWidth(s string) int {
+	width := 0
+	for i := 0; i < len(s); {
+		if n := escapeLen(s[i:]); n > 0 {
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		width += runeWidth(r)
+		i += size
+	}
+	return width
+}
+
+// truncateWidth shortens s to at most width columns, ending it with an
+// ellipsis when anything was cut. Escape sequences are all kept so colors
+// and hyperlinks are still closed.
+func truncateWidth(s string, width int) string {
+	if displayWidth(s) <= width {
+		return s
+	}
+	ellipsis := theme.symbol("…")
+	limit := width - displayWidth(ellipsis)
+
+	var b strings.Builder
+	used, cut := 0, false
+	for i := 0; i < len(s); {
+		if n := escapeLen(s[i:]); n > 0 {
+			b.WriteString(s[i : i+n])
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		if cut {
+			continue
+		}
+		if w := runeWidth(r); used+w > limit {
+			if used+displayWidth(ellipsis) <= width {
+				b.WriteString(ellipsis)
+			}
+			cut = true
+		} else {
+			b.WriteRune(r)
+			used += w
+		}
+	}
+	return b.String()
+}
+
+// padWidth pads s with spaces to width columns, on the left when right is
+// set.
+func padWidth(s string, width int, right bool) string {
+	pad := strings.Repeat(" ", max(width-displayWidth(s), 0))
+	if right {
+		return pad + s
+	}
+	return s + pad
+}
+
+// terminalWidth is the width of the terminal output goes to, $COLUMNS when
+// it isn't a terminal, or 0 when neither is known and tables can be as wide
+// as they like.
+func terminalWidth() int {
+	if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && width > 0 {
+		return width
+	}
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width
+	}
+	return 0
+}
+
+type Column struct {
+	Header string
+	Right  bool // Right-align, for numbers
+	Flex   bool // Shrinks, truncating cells, when the table is too wide
+	Min    int  // Narrowest a Flex column shrinks to
+}
+
+// Table lays out rows in aligned columns measured by display width, so
+// non-ASCII titles and emoji line up. When it would be wider than the
+// terminal its Flex columns are shrunk to fit.
+type Table struct {
+	Title   string // Printed above the table with a double rule, if set
+	Indent  string
+	Columns []Column
+	Rows    [][]string
+}
+
+func (t *Table) AddRow(cells ...string) {
+	t.Rows = append(t.Rows, cells)
+}
+
+func (t *Table) hasHeader() bool {
+	for _, c := range t.Columns {
+		if c.Header != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// widths returns each column's width after fitting the table to the
+// terminal.
+func (t *Table) widths() []int {
+	widths := make([]int, len(t.Columns))
+	for i, c := range t.Columns {
+		widths[i] = displayWidth(c.Header)
+	}
+	for _, row := range t.Rows {
+		for i, cell := range row {
+			if i < len(widths) {
+				widths[i] = max(widths[i], displayWidth(cell))
+			}
+		}
+	}
+
+	limit := terminalWidth()
+	if limit == 0 {
+		return widths
+	}
+	total := displayWidth(t.Indent) + len(widths) - 1
+	for _, w := range widths {
+		total += w
+	}
+	// Take the excess from the widest Flex column until it fits or none can
+	// shrink any further
+	for total > limit-1 {
+		widest := -1
+		for i, c := range t.Columns {
+			if c.Flex && widths[i] > max(c.Min, 1) && (widest < 0 || widths[i] > widths[widest]) {
+				widest = i
+			}
+		}
+		if widest < 0 {
+			break
+		}
+		widths[widest]--
+		total--
+	}
+	return widths
+}
+
+// Lines renders the table without its title.
+func (t *Table) Lines() []string {
+	widths := t.widths()
+	width := len(widths) - 1
+	for _, w := range widths {
+		width += w
+	}
+
+	format := func(cells []string) string {
+		parts := make([]string, len(t.Columns))
+		for i, c := range t.Columns {
+			var cell string
+			if i < len(cells) {
+				cell = truncateWidth(cells[i], widths[i])
+			}
+			if i == len(t.Columns)-1 && !c.Right {
+				parts[i] = cell // No trailing spaces
+			} else {
+				parts[i] = padWidth(cell, widths[i], c.Right)
+			}
+		}
+		return t.Indent + strings.Join(parts, " ")
+	}
+
+	var lines []string
+	if t.hasHeader() {
+		var headers []string
+		for _, c := range t.Columns {
+			headers = append(headers, c.Header)
+		}
+		lines = append(lines, format(headers), t.Indent+theme.rule("─", width))
+	}
+	for _, row := range t.Rows {
+		lines = append(lines, format(row))
+	}
+	return lines
+}
+
+// listingTable is the numbered problem list shared by study, search and
+// related, with any extra columns after the difficulty.
+func listingTable(title string, extra ...string) Table {
+	t := Table{
+		Title: title,
+		Columns: []Column{
+			{Header: "#", Right: true},
+			{Header: "Ref"},
+			{Header: "Problem", Flex: true, Min: 12},
+			{Header: "Difficulty"},
+		},
+	}
+	for _, header := range extra {
+		t.Columns = append(t.Columns, Column{Header: header, Flex: true, Min: 8})
+	}
+	return t
+}
+
+// countTable lists names with how many problems each has, for tags and
+// companies.
+func countTable(title string) Table {
+	return Table{
+		Title:   title,
+		Indent:  "  ",
+		Columns: []Column{{Flex: true, Min: 10}, {Right: true}, {}},
+	}
+}
+
+func (t *Table) Print() {
+	t.PrintRows(0, len(t.Rows))
+}
+
+// PrintRows prints the title, header and rows[start:end], sized to fit all
+// the rows so pages of one table line up.
+func (t *Table) PrintRows(start, end int) {
+	lines := t.Lines()
+	rows := lines[len(lines)-len(t.Rows):]
+	lines = append(lines[:len(lines)-len(t.Rows)], rows[start:end]...)
+	if t.Title != "" {
+		width := 0
+		for _, line := range lines {
+			width = max(width, displayWidth(line))
+		}
+		fmt.Println(t.Title)
+		fmt.Println(theme.rule("═", max(width, displayWidth(t.Title))))
+	}
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+}
+
+// pageTable shows a table size rows at a time, asking which page to show
+// next.
+func pageTable(table Table, size int) error {
+	pages := (len(table.Rows) + size - 1) / size
+	page := 0
+	for {
+		start := page * size
+		table.PrintRows(start, min(start+size, len(table.Rows)))
+
+		next := "Enter for more, "
+		if page == pages-1 {
+			next = "Enter to finish, "
+		}
+		fmt.Printf("Page %d of %d. %sp for previous, a page number, or q to quit: ", page+1, pages, next)
+		input, err := stdin.ReadString('\n')
+		if err != nil {
+			fmt.Println()
+			return nil
+		}
+
+		switch response := strings.TrimSpace(strings.ToLower(input)); response {
+		case "q", "quit":
+			return nil
+		case "p", "prev":
+			page = max(page-1, 0)
+		case "", "n", "next":
+			if page == pages-1 {
+				return nil
+			}
+			page++
+		default:
+			n, err := strconv.Atoi(response)
+			if err != nil || n < 1 || n > pages {
+				fmt.Printf("Pick a page from 1 to %d.\n", pages)
+				continue
+			}
+			page = n - 1
+		}
+	}
+}
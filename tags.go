@@ -0,0 +1,216 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ==================== Tags ====================
+
+type TagCount struct {
+	Name  string
+	Count int
+}
+
+// normalizeTag lowercases a tag and joins words with dashes so
+// "Asked at Google" and "asked-at-google" are the same tag.
+func normalizeTag(tag string) string {
+	return strings.Join(strings.Fields(strings.ToLower(tag)), "-")
+}
+
+func addProblemTag(db execer, problemID int, tag string) error {
+	tag = normalizeTag(tag)
+	if tag == "" {
+		return fmt.Errorf("tag name cannot be empty")
+	}
+
+	if _, err := db.Exec("INSERT OR IGNORE INTO tags (name) VALUES (?)", tag); err != nil {
+		return fmt.Errorf("create tag %q: %w", tag, err)
+	}
+	_, err := db.Exec(`
+		INSERT OR IGNORE INTO problem_tags (problem_id, tag_id)
+		SELECT ?, id FROM tags WHERE name = ?
+	`, problemID, tag)
+	if err != nil {
+		return fmt.Errorf("tag problem: %w", err)
+	}
+	return nil
+}
+
+// removeProblemTag untags a problem and reports whether it had the tag.
+// Tags no longer used by any problem are deleted.
+func removeProblemTag(db *sql.DB, problemID int, tag string) (bool, error) {
+	res, err := db.Exec(`
+		DELETE FROM problem_tags
+		WHERE problem_id = ? AND tag_id = (SELECT id FROM tags WHERE name = ?)
+	`, problemID, normalizeTag(tag))
+	if err != nil {
+		return false, fmt.Errorf("untag problem: %w", err)
+	}
+	removed, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := db.Exec("DELETE FROM tags WHERE id NOT IN (SELECT tag_id FROM problem_tags)"); err != nil {
+		return false, fmt.Errorf("prune tags: %w", err)
+	}
+	return removed > 0, nil
+}
+
+func getProblemTags(db *sql.DB, problemID int) ([]string, error) {
+	rows, err := db.Query(`
+		SELECT t.name
+		FROM tags t
+		INNER JOIN problem_tags pt ON pt.tag_id = t.id
+		WHERE pt.problem_id = ?
+		ORDER BY t.name
+	`, problemID)
+	if err != nil {
+		return nil, fmt.Errorf("query tags: %w", err)
+	}
+	defer rows.Close()
+
+	var tags []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("scan tag: %w", err)
+		}
+		tags = append(tags, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate rows: %w", err)
+	}
+	return tags, nil
+}
+
+// getAllProblemTags maps problem IDs to their tags.
+func getAllProblemTags(db *sql.DB) (map[int][]string, error) {
+	rows, err := db.Query(`
+		SELECT pt.problem_id, t.name
+		FROM problem_tags pt
+		INNER JOIN tags t ON t.id = pt.tag_id
+		ORDER BY t.name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := map[int][]string{}
+	for rows.Next() {
+		var id int
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, fmt.Errorf("scan tag: %w", err)
+		}
+		tags[id] = append(tags[id], name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate rows: %w", err)
+	}
+	return tags, nil
+}
+
+func getTagCounts(db *sql.DB) ([]TagCount, error) {
+	rows, err := db.Query(`
+		SELECT t.name, COUNT(pt.problem_id)
+		FROM tags t
+		LEFT JOIN problem_tags pt ON pt.tag_id = t.id
+		GROUP BY t.id
+		ORDER BY t.name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query tags: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []TagCount
+	for rows.Next() {
+		var c TagCount
+		if err := rows.Scan(&c.Name, &c.Count); err != nil {
+			return nil, fmt.Errorf("scan tag: %w", err)
+		}
+		counts = append(counts, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate rows: %w", err)
+	}
+	return counts, nil
+}
+
+func tagCommandWithDB(db *sql.DB, args []string) error {
+	usage := fmt.Errorf("usage: tag add|remove <tag> <title|LC number>, or tag list [title|LC number]")
+	if len(args) == 0 {
+		return usage
+	}
+
+	switch args[0] {
+	case "add", "remove", "rm":
+		if len(args) < 3 {
+			return usage
+		}
+		tag := normalizeTag(args[1])
+		problem, err := resolveProblem(db, strings.Join(args[2:], " "))
+		if err != nil {
+			return err
+		}
+
+		if args[0] == "add" {
+			if err := addProblemTag(db, problem.ID, tag); err != nil {
+				return err
+			}
+			fmt.Println(success("Tagged '%s' with %s", problem.Title, tag))
+			return nil
+		}
+
+		removed, err := removeProblemTag(db, problem.ID, tag)
+		if err != nil {
+			return err
+		}
+		if !removed {
+			return fmt.Errorf("'%s' is not tagged %s", problem.Title, tag)
+		}
+		fmt.Println(success("Removed tag %s from '%s'", tag, problem.Title))
+		return nil
+
+	case "list", "ls":
+		if len(args) > 1 {
+			problem, err := resolveProblem(db, strings.Join(args[1:], " "))
+			if err != nil {
+				return err
+			}
+			tags, err := getProblemTags(db, problem.ID)
+			if err != nil {
+				return err
+			}
+			if len(tags) == 0 {
+				fmt.Printf("'%s' has no tags.\n", problem.Title)
+				return nil
+			}
+			fmt.Printf("%s: %s\n", problem.Title, strings.Join(tags, ", "))
+			return nil
+		}
+
+		counts, err := getTagCounts(db)
+		if err != nil {
+			return err
+		}
+		if len(counts) == 0 {
+			fmt.Println("\nNo tags yet. Add one with 'tag add <tag> <problem>'.")
+			return nil
+		}
+		table := countTable("\n" + theme.icon("🏷") + "Tags:")
+		for _, c := range counts {
+			table.AddRow(c.Name, strconv.Itoa(c.Count), "problems")
+		}
+		table.Print()
+		fmt.Println()
+		return nil
+	}
+
+	return usage
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// ==================== Theme ====================
+
+// Themes, chosen with --theme or "theme" in config.json.
+const (
+	themeColor        = "color"
+	themeNoColor      = "no-color"
+	themeHighContrast = "high-contrast"
+	themeASCII        = "ascii" // No color, emoji or box drawing; for screen readers and logs
+)
+
+var themeNames = []string{themeColor, themeNoColor, themeHighContrast, themeASCII}
+
+// Style roles, mapped to colors by the theme.
+const (
+	roleSuccess = "success"
+	roleError   = "error"
+	roleWarning = "warning"
+	roleEasy    = "easy"
+	roleMedium  = "medium"
+	roleHard    = "hard"
+	roleAccent  = "accent"
+)
+
+var colorCodes = map[string]string{
+	roleSuccess: "32",
+	roleError:   "31",
+	roleWarning: "33",
+	roleEasy:    "32",
+	roleMedium:  "33",
+	roleHard:    "31",
+	roleAccent:  "36",
+}
+
+// highContrastCodes use bold, bright colors, and keep Easy and Hard apart for
+// readers who can't tell red from green.
+var highContrastCodes = map[string]string{
+	roleSuccess: "1;96",
+	roleError:   "1;93;41",
+	roleWarning: "1;93",
+	roleEasy:    "1;96",
+	roleMedium:  "1;93",
+	roleHard:    "1;95",
+	roleAccent:  "1;97",
+}
+
+// asciiSymbols replaces symbols in the ASCII theme. Decorative emoji that
+// aren't listed are dropped.
+var asciiSymbols = map[string]string{
+	"✓": "OK",
+	"✗": "X",
+	"⚠": "!",
+	"ℹ": "i",
+	"═": "=",
+	"─": "-",
+	"█": "#",
+	"▓": "%",
+	"▒": "+",
+	"░": ":",
+	"·": ".",
+	"┌": "+",
+	"┐": "+",
+	"└": "+",
+	"┘": "+",
+	"│": "|",
+	"↑": "Up",
+	"↓": "Down",
+	"▏": "_",
+	"…": "~",
+}
+
+type Theme struct {
+	Name         string
+	Color        bool
+	HighContrast bool
+	ASCII        bool
+}
+
+// theme is set up once at startup by setTheme.
+var theme = Theme{Name: themeColor, Color: true}
+
+// setTheme selects a theme by name. Color is dropped whatever the theme when
+// NO_COLOR is set or output isn't a terminal, unless the theme was chosen
+// explicitly with --theme.
+func setTheme(name string, explicit bool) error {
+	if name == "" {
+		name = themeColor
+	}
+	if !slices.Contains(themeNames, name) {
+		return fmt.Errorf("unknown theme %q (use %s)", name, strings.Join(themeNames, ", "))
+	}
+
+	theme = Theme{
+		Name:         name,
+		Color:        name == themeColor || name == themeHighContrast,
+		HighContrast: name == themeHighContrast,
+		ASCII:        name == themeASCII,
+	}
+	if !explicit && (os.Getenv("NO_COLOR") != "" || !isTerminal(os.Stdout)) {
+		theme.Color = false
+	}
+	return nil
+}
+
+// paint colors text for a role, or returns it unchanged without color.
+func (t Theme) paint(role, text string) string {
+	if !t.Color {
+		return text
+	}
+	code := colorCodes[role]
+	if t.HighContrast {
+		code = highContrastCodes[role]
+	}
+	return "\033[" + code + "m" + text + "\033[0m"
+}
+
+// icon returns symbol followed by a space, its ASCII stand-in, or nothing
+// for decorative emoji in the ASCII theme.
+func (t Theme) icon(symbol string) string {
+	if !t.ASCII {
+		return symbol + " "
+	}
+	if s, ok := asciiSymbols[symbol]; ok {
+		return s + " "
+	}
+	return ""
+}
+
+// symbol returns a drawing character, or its ASCII stand-in.
+func (t Theme) symbol(s string) string {
+	if t.ASCII {
+		if ascii, ok := asciiSymbols[s]; ok {
+			return ascii
+		}
+	}
+	return s
+}
+
+// rule is a horizontal line of width characters, e.g. rule("═", 59).
+func (t Theme) rule(s string, width int) string {
+	return strings.Repeat(t.symbol(s), width)
+}
+
+// success formats a confirmation: a check mark, in green where there's color.
+func success(format string, a ...any) string {
+	return theme.paint(roleSuccess, theme.icon("✓")+fmt.Sprintf(format, a...))
+}
+
+// failure formats a wrong answer or failed step.
+func failure(format string, a ...any) string {
+	return theme.paint(roleError, theme.icon("✗")+fmt.Sprintf(format, a...))
+}
+
+func warning(format string, a ...any) string {
+	return theme.paint(roleWarning, theme.icon("⚠")+fmt.Sprintf(format, a...))
+}
+
+// difficultyRole maps a difficulty to its color role.
+func difficultyRole(difficulty string) string {
+	switch strings.ToLower(difficulty) {
+	case "easy":
+		return roleEasy
+	case "hard":
+		return roleHard
+	}
+	return roleMedium
+}
@@ -1,14 +1,52 @@
 package main
 
+import (
+	"flag"
+	"fmt"
+)
+
 type CliCommand struct {
 	Name        string
 	Description string
+	Usage       string   // Synopsis for 'help <command>'; the name alone when empty
+	Examples    []string // Shown by 'help <command>'
+	Flags       func() []*flag.FlagSet
 	Callback    func(args []string) error
 }
 
 type Problem struct {
-	Title          string `json:"title"`
-	Difficulty     string `json:"difficulty"`
-	Grouping       string `json:"grouping"`
-	LeetcodeNumber int    `json:"leetcode_number"`
+	ID             int          `json:"-"`
+	Title          string       `json:"title"`
+	Difficulty     string       `json:"difficulty"`
+	Grouping       string       `json:"grouping"`
+	LeetcodeNumber int          `json:"leetcode_number"`
+	Source         string       `json:"source,omitempty"` // "leetcode" when empty
+	Slug           string       `json:"slug,omitempty"`   // Derived from the title when empty
+	URL            string       `json:"url,omitempty"`    // Overrides the LeetCode URL
+	Notes          string       `json:"notes,omitempty"`
+	Tags           []string     `json:"tags,omitempty"`
+	Companies      []CompanyTag `json:"companies,omitempty"`
+	Hints          []string     `json:"hints,omitempty"`   // Ordered like hintLevels
+	Related        []string     `json:"related,omitempty"` // Titles or LeetCode numbers
+	Complexity     *Complexity  `json:"complexity,omitempty"`
+}
+
+// CompanyTag records how often a company asks a problem. Frequency is on a
+// 0-100 scale; Recency is the 0-1 share of those asks in the last six months.
+type CompanyTag struct {
+	Name      string  `json:"name"`
+	Frequency float64 `json:"frequency"`
+	Recency   float64 `json:"recency"`
+}
+
+// Ref is a short reference for listings: "LC 1" for LeetCode problems,
+// otherwise the source name.
+func (p Problem) Ref() string {
+	if p.LeetcodeNumber > 0 {
+		return fmt.Sprintf("LC %d", p.LeetcodeNumber)
+	}
+	if p.Source == "" {
+		return sourceLeetCode
+	}
+	return p.Source
 }